@@ -2,21 +2,91 @@ package comment
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"movies-review-api/domain"
+	"os"
+	"strconv"
+	"strings"
+	"time"
 )
 
+const defaultMaxReplyDepth = 3
+
 type commentUsecase struct {
-	commentRepo domain.CommentRepository
+	commentRepo   domain.CommentRepository
+	revisionRepo  domain.CommentRevisionRepository
+	reactionRepo  domain.ReactionRepository
+	filmRepo      domain.FilmRepository
+	maxReplyDepth int64
+	// allowAnonymous enables anonymous comments on every film.
+	allowAnonymous bool
+	// requireVerifiedEmail blocks users who did not verify their email.
+	requireVerifiedEmail bool
+	filters              []ModerationFilter
 }
 
 func (u commentUsecase) AddComment(ctx context.Context, data *domain.NewCommentRequest) (*domain.Comment, error) {
 
 	comment := domain.Comment{
-		FilmId:  data.FilmId,
-		Summary: data.Summary,
-		UserId:  data.UserId,
+		FilmId:    data.FilmId,
+		Summary:   data.Summary,
+		UserId:    data.UserId,
+		Anonymous: data.UserId == "",
+		AuthorIp:  data.AuthorIp,
+	}
+
+	if !comment.Anonymous && u.requireVerifiedEmail && !data.EmailVerified {
+		return nil, domain.ErrEmailNotVerified
+	}
+
+	if comment.Anonymous && !u.allowAnonymous {
+		film, err := u.filmRepo.GetById(ctx, data.FilmId)
+
+		if err != nil {
+			return nil, err
+		}
+
+		if !film.AllowAnonymousComments {
+			return nil, domain.ErrAnonymousCommentsDisabled
+		}
+	}
+
+	if data.ParentId != "" {
+		parent, err := u.commentRepo.GetById(ctx, data.ParentId)
+
+		if err != nil {
+			return nil, err
+		}
+
+		if parent.FilmId != data.FilmId {
+			return nil, errors.New("parent comment belongs to another film")
+		}
+
+		if parent.Depth+1 > u.maxReplyDepth {
+			return nil, fmt.Errorf("replies cannot be nested more than %d levels deep", u.maxReplyDepth)
+		}
+
+		comment.ParentId = parent.ID.Hex()
+		comment.Depth = parent.Depth + 1
+	}
+
+	verdict, reasons, err := moderate(ctx, u.filters, &comment)
+
+	if err != nil {
+		return nil, err
 	}
 
+	switch verdict {
+	case Reject:
+		return nil, fmt.Errorf("%w: %s", domain.ErrCommentRejected, strings.Join(reasons, ", "))
+	case Hold:
+		comment.Status = domain.CommentStatusHeld
+	default:
+		comment.Status = domain.CommentStatusApproved
+	}
+	comment.ModerationReasons = reasons
+
 	newComment, err := u.commentRepo.Create(ctx, &comment)
 
 	if err != nil {
@@ -26,8 +96,150 @@ func (u commentUsecase) AddComment(ctx context.Context, data *domain.NewCommentR
 	return newComment, nil
 }
 
-func New(u domain.CommentRepository) domain.CommentUsecase {
+func (u commentUsecase) UpdateComment(ctx context.Context, id, userId string, data *domain.UpdateCommentRequest) (*domain.Comment, error) {
+	comment, err := u.commentRepo.GetById(ctx, id)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if comment.UserId != userId {
+		return nil, domain.ErrCommentForbidden
+	}
+
+	if comment.Summary == data.Summary {
+		return comment, nil
+	}
+
+	now := time.Now().UTC()
+	comment.Summary = data.Summary
+	comment.EditedAt = &now
+	comment.EditedBy = userId
+
+	verdict, reasons, err := moderate(ctx, u.filters, comment)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if verdict == Reject {
+		return nil, fmt.Errorf("%w: %s", domain.ErrCommentRejected, strings.Join(reasons, ", "))
+	}
+
+	comment, err = u.commentRepo.Update(ctx, comment)
+
+	if err != nil || verdict != Hold {
+		return comment, err
+	}
+
+	// an edit held by moderation hides the comment again until approved
+	return comment, u.commentRepo.Hold(ctx, comment, reasons)
+}
+
+func (u commentUsecase) DeleteComment(ctx context.Context, id, userId string) error {
+	comment, err := u.commentRepo.GetById(ctx, id)
+
+	if err != nil {
+		return err
+	}
+
+	if comment.UserId != userId {
+		return domain.ErrCommentForbidden
+	}
+
+	return u.commentRepo.SoftDelete(ctx, comment)
+}
+
+func (u commentUsecase) FetchCommentRevisions(ctx context.Context, id, userId, role string, page, limit int64) (*domain.PaginatedCommentRevision, error) {
+	comment, err := u.commentRepo.GetById(ctx, id)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if comment.UserId != userId && !domain.IsModerator(role) {
+		return nil, domain.ErrCommentForbidden
+	}
+
+	return u.revisionRepo.FetchPaginatedCommentRevisions(ctx, comment.ID.Hex(), page, limit)
+}
+
+func (u commentUsecase) FetchCommentReplies(ctx context.Context, id string, page, limit int64) (*domain.PaginatedComment, error) {
+	comment, err := u.commentRepo.GetById(ctx, id)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return u.commentRepo.FetchPaginatedCommentReplies(ctx, comment.ID.Hex(), page, limit)
+}
+
+func (u commentUsecase) AddReaction(ctx context.Context, id, userId, reactionType string) (*domain.Reaction, error) {
+	if !domain.IsReactionType(reactionType) {
+		return nil, fmt.Errorf("unknown reaction type %s", reactionType)
+	}
+
+	comment, err := u.commentRepo.GetById(ctx, id)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return u.reactionRepo.Create(ctx, &domain.Reaction{
+		CommentId: comment.ID.Hex(),
+		UserId:    userId,
+		Type:      reactionType,
+	})
+}
+
+func (u commentUsecase) RemoveReaction(ctx context.Context, id, userId, reactionType string) error {
+	if !domain.IsReactionType(reactionType) {
+		return fmt.Errorf("unknown reaction type %s", reactionType)
+	}
+
+	comment, err := u.commentRepo.GetById(ctx, id)
+
+	if err != nil {
+		return err
+	}
+
+	return u.reactionRepo.Delete(ctx, comment.ID.Hex(), userId, reactionType)
+}
+
+func (u commentUsecase) FetchCommentReactions(ctx context.Context, id, reactionType string, page, limit int64) (*domain.PaginatedReaction, error) {
+	if reactionType != "" && !domain.IsReactionType(reactionType) {
+		return nil, fmt.Errorf("unknown reaction type %s", reactionType)
+	}
+
+	comment, err := u.commentRepo.GetById(ctx, id)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return u.reactionRepo.FetchPaginatedCommentReactions(ctx, comment.ID.Hex(), reactionType, page, limit)
+}
+
+// New returns a domain.CommentUsecase. Replies can be nested up to
+// COMMENT_MAX_REPLY_DEPTH levels, 3 by default. New comments and edits go
+// through the moderation filters in order.
+func New(u domain.CommentRepository, revisionRepo domain.CommentRevisionRepository, reactionRepo domain.ReactionRepository, filmRepo domain.FilmRepository, filters ...ModerationFilter) domain.CommentUsecase {
+	maxReplyDepth, err := strconv.ParseInt(os.Getenv("COMMENT_MAX_REPLY_DEPTH"), 10, 64)
+	if err != nil || maxReplyDepth < 0 {
+		maxReplyDepth = defaultMaxReplyDepth
+	}
+
+	allowAnonymous, _ := strconv.ParseBool(os.Getenv("COMMENT_ANONYMOUS_ENABLED"))
+	requireVerifiedEmail, _ := strconv.ParseBool(os.Getenv("COMMENT_REQUIRE_VERIFIED_EMAIL"))
+
 	return &commentUsecase{
-		commentRepo: u,
+		commentRepo:          u,
+		revisionRepo:         revisionRepo,
+		reactionRepo:         reactionRepo,
+		filmRepo:             filmRepo,
+		maxReplyDepth:        maxReplyDepth,
+		allowAnonymous:       allowAnonymous,
+		requireVerifiedEmail: requireVerifiedEmail,
+		filters:              filters,
 	}
 }
@@ -0,0 +1,323 @@
+package comment
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"movies-review-api/domain"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// Verdict is what a ModerationFilter decides about a comment.
+type Verdict int
+
+const (
+	Approve Verdict = iota
+	Hold
+	Reject
+)
+
+func (v Verdict) String() string {
+	switch v {
+	case Hold:
+		return "hold"
+	case Reject:
+		return "reject"
+	}
+	return "approve"
+}
+
+func parseVerdict(s string) (Verdict, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "approve":
+		return Approve, nil
+	case "hold":
+		return Hold, nil
+	case "reject":
+		return Reject, nil
+	}
+	return Approve, fmt.Errorf("unknown verdict %q", s)
+}
+
+// ModerationResult is the verdict of a filter and why it was reached.
+type ModerationResult struct {
+	Verdict Verdict
+	Reason  string
+}
+
+var approved = ModerationResult{Verdict: Approve}
+
+// ModerationFilter checks a comment before it is saved or edited.
+type ModerationFilter interface {
+	Name() string
+	Moderate(ctx context.Context, comment *domain.Comment) (ModerationResult, error)
+}
+
+// moderate runs every filter on the comment. A single reject rejects the
+// comment, otherwise it is held when any filter holds it. The reasons of
+// every filter that did not approve are returned.
+func moderate(ctx context.Context, filters []ModerationFilter, comment *domain.Comment) (Verdict, []string, error) {
+	verdict := Approve
+	var reasons []string
+
+	for _, filter := range filters {
+		result, err := filter.Moderate(ctx, comment)
+		if err != nil {
+			return Approve, nil, fmt.Errorf("%s filter: %w", filter.Name(), err)
+		}
+
+		if result.Verdict == Approve {
+			continue
+		}
+
+		reasons = append(reasons, fmt.Sprintf("%s: %s", filter.Name(), result.Reason))
+		if result.Verdict > verdict {
+			verdict = result.Verdict
+		}
+	}
+
+	return verdict, reasons, nil
+}
+
+// WordListFilter gives its verdict to comments containing any of its words
+// or phrases. Both are compared in lowercase with punctuation dropped.
+type WordListFilter struct {
+	Words   []string
+	Verdict Verdict
+}
+
+func (f WordListFilter) Name() string {
+	return "word_list"
+}
+
+func (f WordListFilter) Moderate(ctx context.Context, comment *domain.Comment) (ModerationResult, error) {
+	// padded so entries only match whole words
+	text := " " + normalizeWords(comment.Summary) + " "
+
+	for _, listed := range f.Words {
+		if listed = normalizeWords(listed); listed != "" && strings.Contains(text, " "+listed+" ") {
+			return ModerationResult{Verdict: f.Verdict, Reason: fmt.Sprintf("contains %q", listed)}, nil
+		}
+	}
+
+	return approved, nil
+}
+
+// normalizeWords lowercases s and joins its words with single spaces.
+func normalizeWords(s string) string {
+	return strings.Join(strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	}), " ")
+}
+
+// SpamFilter holds comments that look like spam: too many links, mostly
+// capital letters or a character repeated over and over.
+type SpamFilter struct {
+	MaxLinks int
+}
+
+func (f SpamFilter) Name() string {
+	return "spam"
+}
+
+func (f SpamFilter) Moderate(ctx context.Context, comment *domain.Comment) (ModerationResult, error) {
+	summary := strings.ToLower(comment.Summary)
+
+	links := strings.Count(summary, "http://") + strings.Count(summary, "https://") + strings.Count(summary, "www.")
+	if links > f.MaxLinks {
+		return ModerationResult{Verdict: Hold, Reason: fmt.Sprintf("contains %d links", links)}, nil
+	}
+
+	var letters, upper, repeated int
+	var last rune
+	for _, r := range comment.Summary {
+		if unicode.IsLetter(r) {
+			letters++
+			if unicode.IsUpper(r) {
+				upper++
+			}
+		}
+
+		if r == last && !unicode.IsSpace(r) {
+			repeated++
+			if repeated >= 9 {
+				return ModerationResult{Verdict: Hold, Reason: fmt.Sprintf("repeats %q", r)}, nil
+			}
+		} else {
+			repeated = 0
+		}
+		last = r
+	}
+
+	if letters >= 20 && upper*10 > letters*7 {
+		return ModerationResult{Verdict: Hold, Reason: "mostly capital letters"}, nil
+	}
+
+	return approved, nil
+}
+
+// DuplicateFilter rejects a comment its author already posted on the same
+// film within the window.
+type DuplicateFilter struct {
+	CommentRepo domain.CommentRepository
+	Window      time.Duration
+}
+
+func (f DuplicateFilter) Name() string {
+	return "duplicate"
+}
+
+func (f DuplicateFilter) Moderate(ctx context.Context, comment *domain.Comment) (ModerationResult, error) {
+	duplicate, err := f.CommentRepo.HasRecentDuplicate(ctx, comment, time.Now().UTC().Add(-f.Window))
+	if err != nil {
+		return approved, err
+	}
+
+	if duplicate {
+		return ModerationResult{Verdict: Reject, Reason: "already posted"}, nil
+	}
+
+	return approved, nil
+}
+
+// Classifier is an external service scoring comments.
+type Classifier interface {
+	Classify(ctx context.Context, text string) (ModerationResult, error)
+}
+
+// ClassifierFilter asks a Classifier about the comment. Comments are held
+// when the classifier cannot be reached.
+type ClassifierFilter struct {
+	Classifier Classifier
+}
+
+func (f ClassifierFilter) Name() string {
+	return "classifier"
+}
+
+func (f ClassifierFilter) Moderate(ctx context.Context, comment *domain.Comment) (ModerationResult, error) {
+	result, err := f.Classifier.Classify(ctx, comment.Summary)
+	if err != nil {
+		return ModerationResult{Verdict: Hold, Reason: "classifier unavailable"}, nil
+	}
+	return result, nil
+}
+
+// HTTPClassifier posts {"text": ...} to Url and expects
+// {"verdict": "approve|hold|reject", "reason": ...} back.
+type HTTPClassifier struct {
+	Url    string
+	Client *http.Client
+}
+
+func (c HTTPClassifier) Classify(ctx context.Context, text string) (ModerationResult, error) {
+	body, err := json.Marshal(map[string]string{"text": text})
+	if err != nil {
+		return approved, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.Url, bytes.NewReader(body))
+	if err != nil {
+		return approved, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.Client.Do(req)
+	if err != nil {
+		return approved, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return approved, fmt.Errorf("classifier responded with status %d", resp.StatusCode)
+	}
+
+	var data struct {
+		Verdict string `json:"verdict"`
+		Reason  string `json:"reason"`
+	}
+	if err = json.NewDecoder(resp.Body).Decode(&data); err != nil {
+		return approved, err
+	}
+
+	verdict, err := parseVerdict(data.Verdict)
+	if err != nil {
+		return approved, err
+	}
+
+	return ModerationResult{Verdict: verdict, Reason: data.Reason}, nil
+}
+
+// StubClassifier gives the same verdict to every comment, for local runs.
+type StubClassifier struct {
+	Verdict Verdict
+}
+
+func (c StubClassifier) Classify(ctx context.Context, text string) (ModerationResult, error) {
+	return ModerationResult{Verdict: c.Verdict, Reason: "stub classifier"}, nil
+}
+
+// DefaultModerationFilters builds the moderation filters from the config:
+//   - COMMENT_BLOCKED_WORDS and COMMENT_HELD_WORDS, comma separated words
+//     or phrases rejecting or holding a comment
+//   - COMMENT_MAX_LINKS, 2 by default, before a comment is held as spam
+//   - COMMENT_DUPLICATE_WINDOW, 10m by default, 0 to allow duplicates
+//   - COMMENT_CLASSIFIER_URL, an external classifier, or stub://<verdict>
+//     to give every comment the same verdict
+//
+// An unknown stub verdict is an error rather than a disabled classifier.
+func DefaultModerationFilters(commentRepo domain.CommentRepository) ([]ModerationFilter, error) {
+	var filters []ModerationFilter
+
+	if words := wordsFromEnv("COMMENT_BLOCKED_WORDS"); len(words) > 0 {
+		filters = append(filters, WordListFilter{Words: words, Verdict: Reject})
+	}
+	if words := wordsFromEnv("COMMENT_HELD_WORDS"); len(words) > 0 {
+		filters = append(filters, WordListFilter{Words: words, Verdict: Hold})
+	}
+
+	maxLinks, err := strconv.Atoi(os.Getenv("COMMENT_MAX_LINKS"))
+	if err != nil || maxLinks < 0 {
+		maxLinks = 2
+	}
+	filters = append(filters, SpamFilter{MaxLinks: maxLinks})
+
+	window, err := time.ParseDuration(os.Getenv("COMMENT_DUPLICATE_WINDOW"))
+	if err != nil || window < 0 {
+		window = 10 * time.Minute
+	}
+	if window > 0 {
+		filters = append(filters, DuplicateFilter{CommentRepo: commentRepo, Window: window})
+	}
+
+	if url := os.Getenv("COMMENT_CLASSIFIER_URL"); strings.HasPrefix(url, "stub://") {
+		verdict, err := parseVerdict(strings.TrimPrefix(url, "stub://"))
+		if err != nil {
+			return nil, fmt.Errorf("COMMENT_CLASSIFIER_URL: %w", err)
+		}
+		filters = append(filters, ClassifierFilter{Classifier: StubClassifier{Verdict: verdict}})
+	} else if url != "" {
+		filters = append(filters, ClassifierFilter{Classifier: HTTPClassifier{
+			Url:    url,
+			Client: &http.Client{Timeout: 5 * time.Second},
+		}})
+	}
+
+	return filters, nil
+}
+
+func wordsFromEnv(key string) []string {
+	var words []string
+	for _, word := range strings.Split(os.Getenv(key), ",") {
+		if word = normalizeWords(word); word != "" {
+			words = append(words, word)
+		}
+	}
+	return words
+}
@@ -0,0 +1,80 @@
+package comment
+
+import (
+	"context"
+	"movies-review-api/domain"
+	"strings"
+	"testing"
+)
+
+func TestWordListFilter(t *testing.T) {
+	filter := WordListFilter{Words: []string{"spoiler", "darth vader dies", "Jar-Jar"}, Verdict: Hold}
+
+	tests := []struct {
+		summary string
+		want    Verdict
+	}{
+		{summary: "A great film", want: Approve},
+		{summary: "Huge SPOILER ahead!", want: Hold},
+		{summary: "spoilers are fine", want: Approve},
+		{summary: "in the end Darth  Vader, dies.", want: Hold},
+		{summary: "darth vader lives", want: Approve},
+		{summary: "jar jar is back", want: Hold},
+		{summary: "jarjar is back", want: Approve},
+		{summary: "", want: Approve},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.summary, func(t *testing.T) {
+			result, err := filter.Moderate(context.Background(), &domain.Comment{Summary: tt.summary})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if result.Verdict != tt.want {
+				t.Errorf("verdict = %s, want %s (%s)", result.Verdict, tt.want, result.Reason)
+			}
+		})
+	}
+}
+
+func TestSpamFilter(t *testing.T) {
+	filter := SpamFilter{MaxLinks: 2}
+
+	tests := []struct {
+		name    string
+		summary string
+		want    Verdict
+	}{
+		{name: "plain", summary: "The opening crawl still gives me chills.", want: Approve},
+		{name: "two links", summary: "see https://a.example and http://b.example", want: Approve},
+		{name: "three links", summary: "https://a.example http://b.example www.c.example", want: Hold},
+		{name: "shouting", summary: "THIS IS THE BEST FILM EVER MADE, WATCH IT", want: Hold},
+		{name: "short shouting", summary: "WOW", want: Approve},
+		{name: "repeated", summary: "so good" + strings.Repeat("!", 10), want: Hold},
+		{name: "repeated spaces", summary: "so" + strings.Repeat(" ", 12) + "good", want: Approve},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := filter.Moderate(context.Background(), &domain.Comment{Summary: tt.summary})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if result.Verdict != tt.want {
+				t.Errorf("verdict = %s, want %s (%s)", result.Verdict, tt.want, result.Reason)
+			}
+		})
+	}
+}
+
+func TestDefaultModerationFiltersStubVerdict(t *testing.T) {
+	t.Setenv("COMMENT_CLASSIFIER_URL", "stub://hold")
+	if _, err := DefaultModerationFilters(nil); err != nil {
+		t.Errorf("stub://hold: %v", err)
+	}
+
+	t.Setenv("COMMENT_CLASSIFIER_URL", "stub://maybe")
+	if _, err := DefaultModerationFilters(nil); err == nil {
+		t.Error("stub://maybe: expected an error")
+	}
+}
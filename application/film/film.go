@@ -6,36 +6,61 @@ import (
 	"go.uber.org/zap"
 	"movies-review-api/domain"
 	"movies-review-api/pkg/logger"
+	"time"
 )
 
 type filmUsecase struct {
-	filmRepo domain.FilmRepository
-	logger   *zap.Logger
+	filmRepo    domain.FilmRepository
+	syncRunRepo domain.SyncRunRepository
+	filmSources *domain.FilmSourceRegistry
+	logger      *zap.Logger
 }
 
-func (u filmUsecase) FetchFilmsFromAllSources(ctx context.Context, page, limit int64) (*domain.PaginatedFilm, error) {
+func (u filmUsecase) SyncFilmsFromAllSources(ctx context.Context) []domain.SyncRun {
+	var runs []domain.SyncRun
 
-	var externalSource string
-	// check external source for new films
-	err := domain.UpdateFilmFromSource(u.logger, externalSource)
-	if err != nil {
-		u.logger.Error(fmt.Sprintf("error occured while updating film from source %s", zap.Error(err)))
-	}
+	// check external sources for new films
+	for _, source := range u.filmSources.Sources() {
+		run := domain.SyncRun{
+			Source:    source.Name(),
+			StartedAt: time.Now().UTC(),
+		}
+
+		err := domain.UpdateFilmFromSource(ctx, u.logger, source, &run)
+		if catalog, ok := source.(domain.CatalogSource); ok && err == nil {
+			err = domain.UpdateResourcesFromSource(ctx, u.logger, catalog, &run)
+		}
+		if err == nil {
+			err = domain.ResolveSourceRelations(ctx, source.Name(), &run)
+		}
+		if err != nil {
+			u.logger.Error("error occurred while updating film from source", zap.String("source", source.Name()), zap.Error(err))
+			run.Error = err.Error()
+		} else if len(run.Errors) > 0 {
+			// a partially saved sync is a failed one
+			run.Error = fmt.Sprintf("%d items failed to sync", len(run.Errors))
+		}
+
+		run.FinishedAt = time.Now().UTC()
 
-	data, err := u.filmRepo.FetchPaginatedFilms(ctx, page, limit)
+		// record the run even if ctx was cancelled half way through
+		if _, err := u.syncRunRepo.Create(context.Background(), &run); err != nil {
+			u.logger.Error("error occurred while saving sync run", zap.String("source", source.Name()), zap.Error(err))
+		}
 
-	if err != nil {
-		return nil, err
+		runs = append(runs, run)
 	}
 
-	return data, nil
+	return runs
 }
 
-func New(u domain.FilmRepository) domain.FilmUsecase {
+func New(u domain.FilmRepository, syncRunRepo domain.SyncRunRepository, sources *domain.FilmSourceRegistry) domain.FilmUsecase {
 	l, _ := logger.InitLogger()
 
 	return &filmUsecase{
-		filmRepo: u,
-		logger:   l,
+		filmRepo:    u,
+		syncRunRepo: syncRunRepo,
+		filmSources: sources,
+		logger:      l,
 	}
 }
@@ -0,0 +1,174 @@
+package moderation
+
+import (
+	"context"
+	"errors"
+	"movies-review-api/domain"
+	"os"
+	"strconv"
+)
+
+const defaultReportThreshold = 3
+
+type moderationUsecase struct {
+	commentRepo     domain.CommentRepository
+	reportRepo      domain.CommentReportRepository
+	actionRepo      domain.ModerationActionRepository
+	userRepo        domain.UserRepository
+	reportThreshold int64
+}
+
+func (u moderationUsecase) ReportComment(ctx context.Context, id, userId string, data *domain.ReportCommentRequest) (*domain.CommentReport, error) {
+	comment, err := u.commentRepo.GetById(ctx, id)
+
+	if err != nil {
+		return nil, err
+	}
+
+	report, err := u.reportRepo.Create(ctx, &domain.CommentReport{
+		CommentId: comment.ID.Hex(),
+		UserId:    userId,
+		Reason:    data.Reason,
+		Note:      data.Note,
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	// reload the comment for the report count kept by the report hook
+	comment, err = u.commentRepo.GetById(ctx, id)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if comment.IsVisible() && comment.ReportCount >= u.reportThreshold {
+		if err = u.commentRepo.HideReported(ctx, comment); err != nil {
+			return nil, err
+		}
+
+		if err = u.record(ctx, comment, "", domain.ModerationAutoHide, ""); err != nil {
+			return nil, err
+		}
+	}
+
+	return report, nil
+}
+
+func (u moderationUsecase) FetchReportedComments(ctx context.Context, page, limit int64) (*domain.PaginatedComment, error) {
+	return u.commentRepo.FetchPaginatedReportedComments(ctx, page, limit)
+}
+
+func (u moderationUsecase) FetchHeldComments(ctx context.Context, page, limit int64) (*domain.PaginatedComment, error) {
+	return u.commentRepo.FetchPaginatedHeldComments(ctx, page, limit)
+}
+
+func (u moderationUsecase) ApproveComment(ctx context.Context, id, userId string) (*domain.Comment, error) {
+	comment, err := u.commentRepo.GetById(ctx, id)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if !comment.IsHeld() {
+		return nil, errors.New("comment is not held for moderation")
+	}
+
+	if err = u.commentRepo.Approve(ctx, comment); err != nil {
+		return nil, err
+	}
+
+	return comment, u.record(ctx, comment, userId, domain.ModerationApprove, "")
+}
+
+func (u moderationUsecase) RejectComment(ctx context.Context, id, userId string) error {
+	comment, err := u.commentRepo.GetById(ctx, id)
+
+	if err != nil {
+		return err
+	}
+
+	if !comment.IsHeld() {
+		return errors.New("comment is not held for moderation")
+	}
+
+	if err = u.commentRepo.SoftDelete(ctx, comment); err != nil {
+		return err
+	}
+
+	return u.record(ctx, comment, userId, domain.ModerationReject, "")
+}
+
+func (u moderationUsecase) ModerateComment(ctx context.Context, id, userId string, data *domain.ModerateCommentRequest) (*domain.Comment, error) {
+	comment, err := u.commentRepo.GetById(ctx, id)
+
+	if err != nil {
+		return nil, err
+	}
+
+	switch data.Action {
+	case domain.ModerationDismiss:
+		if err = u.reportRepo.DismissAll(ctx, comment.ID.Hex()); err != nil {
+			return nil, err
+		}
+		comment.ReportCount = 0
+
+		// dismissed reports no longer hide the comment, a comment hidden by
+		// a moderator stays hidden
+		if comment.Status == domain.CommentStatusReported {
+			err = u.commentRepo.Approve(ctx, comment)
+		}
+	case domain.ModerationHide:
+		err = u.commentRepo.Hide(ctx, comment)
+	case domain.ModerationDelete:
+		err = u.commentRepo.SoftDelete(ctx, comment)
+	case domain.ModerationBan:
+		if comment.UserId == "" {
+			return nil, errors.New("anonymous authors cannot be banned")
+		}
+		err = u.userRepo.Ban(ctx, comment.UserId)
+	default:
+		return nil, errors.New("unknown moderation action " + data.Action)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return comment, u.record(ctx, comment, userId, data.Action, data.Note)
+}
+
+func (u moderationUsecase) FetchModerationActions(ctx context.Context, id string, page, limit int64) (*domain.PaginatedModerationAction, error) {
+	return u.actionRepo.FetchPaginatedCommentActions(ctx, id, page, limit)
+}
+
+// record keeps a trail of every moderation action.
+func (u moderationUsecase) record(ctx context.Context, comment *domain.Comment, moderatorId, action, note string) error {
+	_, err := u.actionRepo.Create(ctx, &domain.ModerationAction{
+		CommentId:   comment.ID.Hex(),
+		ModeratorId: moderatorId,
+		AuthorId:    comment.UserId,
+		Action:      action,
+		Note:        note,
+	})
+
+	return err
+}
+
+// New returns a domain.ModerationUsecase. Comments are hidden once they
+// have COMMENT_REPORT_THRESHOLD open reports, 3 by default.
+func New(commentRepo domain.CommentRepository, reportRepo domain.CommentReportRepository, actionRepo domain.ModerationActionRepository, userRepo domain.UserRepository) domain.ModerationUsecase {
+	reportThreshold, err := strconv.ParseInt(os.Getenv("COMMENT_REPORT_THRESHOLD"), 10, 64)
+	if err != nil || reportThreshold < 1 {
+		reportThreshold = defaultReportThreshold
+	}
+
+	return &moderationUsecase{
+		commentRepo:     commentRepo,
+		reportRepo:      reportRepo,
+		actionRepo:      actionRepo,
+		userRepo:        userRepo,
+		reportThreshold: reportThreshold,
+	}
+}
@@ -0,0 +1,47 @@
+package rating
+
+import (
+	"context"
+	"movies-review-api/domain"
+)
+
+type ratingUsecase struct {
+	ratingRepo domain.RatingRepository
+	filmRepo   domain.FilmRepository
+}
+
+func (u ratingUsecase) RateFilm(ctx context.Context, filmId, userId string, request *domain.RatingRequest) (*domain.Rating, error) {
+	film, err := u.filmRepo.GetById(ctx, filmId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return u.ratingRepo.Upsert(ctx, &domain.Rating{
+		FilmId: film.ID.Hex(),
+		UserId: userId,
+		Score:  request.Score,
+	})
+}
+
+func (u ratingUsecase) DeleteRating(ctx context.Context, filmId, userId string) error {
+	film, err := u.filmRepo.GetById(ctx, filmId)
+
+	if err != nil {
+		return err
+	}
+
+	return u.ratingRepo.Delete(ctx, film.ID.Hex(), userId)
+}
+
+func (u ratingUsecase) FetchUserRating(ctx context.Context, filmId, userId string) (*domain.Rating, error) {
+	return u.ratingRepo.GetByFilmAndUser(ctx, filmId, userId)
+}
+
+// New returns a domain.RatingUsecase.
+func New(ratingRepo domain.RatingRepository, filmRepo domain.FilmRepository) domain.RatingUsecase {
+	return &ratingUsecase{
+		ratingRepo: ratingRepo,
+		filmRepo:   filmRepo,
+	}
+}
@@ -0,0 +1,123 @@
+package review
+
+import (
+	"context"
+	"errors"
+	"movies-review-api/domain"
+	"time"
+)
+
+type reviewUsecase struct {
+	reviewRepo domain.ReviewRepository
+	voteRepo   domain.ReviewVoteRepository
+	filmRepo   domain.FilmRepository
+}
+
+func (u reviewUsecase) AddReview(ctx context.Context, filmId, userId string, data *domain.ReviewRequest) (*domain.Review, error) {
+	film, err := u.filmRepo.GetById(ctx, filmId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return u.reviewRepo.Create(ctx, &domain.Review{
+		FilmId:  film.ID.Hex(),
+		UserId:  userId,
+		Title:   data.Title,
+		Body:    data.Body,
+		Rating:  data.Rating,
+		Spoiler: data.Spoiler,
+	})
+}
+
+func (u reviewUsecase) UpdateReview(ctx context.Context, filmId, id, userId string, data *domain.ReviewRequest) (*domain.Review, error) {
+	review, err := u.FetchReview(ctx, filmId, id)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if review.UserId != userId {
+		return nil, domain.ErrReviewForbidden
+	}
+
+	review.Title = data.Title
+	review.Body = data.Body
+	review.Rating = data.Rating
+	review.Spoiler = data.Spoiler
+	review.UpdatedAt = time.Now().UTC()
+
+	return u.reviewRepo.Update(ctx, review)
+}
+
+func (u reviewUsecase) DeleteReview(ctx context.Context, filmId, id, userId, role string) error {
+	review, err := u.FetchReview(ctx, filmId, id)
+
+	if err != nil {
+		return err
+	}
+
+	if review.UserId != userId && !domain.IsModerator(role) {
+		return domain.ErrReviewForbidden
+	}
+
+	if err = u.reviewRepo.Delete(ctx, review); err != nil {
+		return err
+	}
+
+	return u.voteRepo.DeleteByReview(ctx, review.ID.Hex())
+}
+
+func (u reviewUsecase) FetchReview(ctx context.Context, filmId, id string) (*domain.Review, error) {
+	review, err := u.reviewRepo.GetById(ctx, id)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if review.FilmId != filmId {
+		return nil, errors.New("resource not found")
+	}
+
+	return review, nil
+}
+
+func (u reviewUsecase) FetchFilmReviews(ctx context.Context, filmId, sort string, page, limit int64) (*domain.PaginatedReview, error) {
+	return u.reviewRepo.FetchPaginatedFilmReviews(ctx, filmId, sort, page, limit)
+}
+
+func (u reviewUsecase) MarkHelpful(ctx context.Context, filmId, id, userId string) (*domain.ReviewVote, error) {
+	review, err := u.FetchReview(ctx, filmId, id)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if review.UserId == userId {
+		return nil, domain.ErrOwnReviewHelpful
+	}
+
+	return u.voteRepo.Create(ctx, &domain.ReviewVote{
+		ReviewId: review.ID.Hex(),
+		UserId:   userId,
+	})
+}
+
+func (u reviewUsecase) UnmarkHelpful(ctx context.Context, filmId, id, userId string) error {
+	review, err := u.FetchReview(ctx, filmId, id)
+
+	if err != nil {
+		return err
+	}
+
+	return u.voteRepo.Delete(ctx, review.ID.Hex(), userId)
+}
+
+// New returns a domain.ReviewUsecase.
+func New(reviewRepo domain.ReviewRepository, voteRepo domain.ReviewVoteRepository, filmRepo domain.FilmRepository) domain.ReviewUsecase {
+	return &reviewUsecase{
+		reviewRepo: reviewRepo,
+		voteRepo:   voteRepo,
+		filmRepo:   filmRepo,
+	}
+}
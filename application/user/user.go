@@ -3,11 +3,22 @@ package user
 import (
 	"context"
 	"errors"
+	"fmt"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 	"movies-review-api/domain"
+	"net/url"
+	"os"
+	"strings"
+	"time"
 )
 
 type userUsecase struct {
-	userRepo domain.UserRepository
+	userRepo         domain.UserRepository
+	refreshTokenRepo domain.RefreshTokenRepository
+	revocationStore  domain.RevocationStore
+	keys             *domain.KeySet
+	verificationRepo domain.EmailVerificationRepository
+	mailer           domain.Mailer
 }
 
 func (u userUsecase) Login(ctx context.Context, data *domain.LoginRequest) (*domain.User, error) {
@@ -21,13 +32,17 @@ func (u userUsecase) Login(ctx context.Context, data *domain.LoginRequest) (*dom
 	if isCorrect := domain.CheckPasswordHash(data.Password, existingUser.Password); !isCorrect {
 		return nil, errors.New("invalid login credentials")
 	}
+
+	if existingUser.BannedAt != nil {
+		return nil, errors.New("account banned")
+	}
 	return existingUser, nil
 }
 
 func (u userUsecase) Signup(ctx context.Context, data *domain.SignupRequest) (*domain.User, error) {
 	existingUser, err := u.userRepo.GetByEmail(ctx, data.Email)
 
-	if err != nil {
+	if err != nil && !errors.Is(err, domain.ErrUserNotFound) {
 		return nil, err
 	}
 
@@ -39,22 +54,232 @@ func (u userUsecase) Signup(ctx context.Context, data *domain.SignupRequest) (*d
 		Firstname: data.Firstname,
 		Lastname:  data.Lastname,
 		Email:     data.Email,
+		Role:      domain.RoleUser,
 	}
 
 	// hash password
 	user.Password, err = domain.HashPassword(data.Password)
 
+	if err != nil {
+		return nil, err
+	}
+
 	newUser, err := u.userRepo.Create(ctx, &user)
 
 	if err != nil {
 		return nil, err
 	}
 
+	// the account exists either way, a new token can be requested later
+	if err = u.sendVerification(ctx, newUser); err != nil {
+		return newUser, fmt.Errorf("%w: %v", domain.ErrVerificationEmailNotSent, err)
+	}
+
 	return newUser, nil
 }
 
-func New(u domain.UserRepository) domain.UserUsecase {
+func (u userUsecase) VerifyEmail(ctx context.Context, token string) (*domain.User, error) {
+	verification, err := u.verificationRepo.GetByHash(ctx, domain.HashVerificationToken(token))
+
+	if err != nil || verification.UsedAt != nil || time.Now().After(verification.ExpiresAt) {
+		return nil, domain.ErrInvalidVerificationToken
+	}
+
+	fresh, err := u.verificationRepo.MarkUsed(ctx, verification)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if !fresh {
+		return nil, domain.ErrInvalidVerificationToken
+	}
+
+	user, err := u.userRepo.GetById(ctx, verification.UserId)
+
+	if err != nil {
+		return nil, domain.ErrInvalidVerificationToken
+	}
+
+	// a token sent before the email changed proves nothing
+	if user.Email != verification.Email {
+		return nil, domain.ErrInvalidVerificationToken
+	}
+
+	if user.IsEmailVerified() {
+		return user, nil
+	}
+
+	if err = u.userRepo.MarkEmailVerified(ctx, user.ID.Hex()); err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	user.EmailVerifiedAt = &now
+
+	return user, nil
+}
+
+func (u userUsecase) ResendVerification(ctx context.Context, userId string) error {
+	user, err := u.userRepo.GetById(ctx, userId)
+
+	if err != nil {
+		return err
+	}
+
+	if user.IsEmailVerified() {
+		return domain.ErrEmailAlreadyVerified
+	}
+
+	if err = u.sendVerification(ctx, user); err != nil {
+		return fmt.Errorf("%w: %v", domain.ErrVerificationEmailNotSent, err)
+	}
+
+	return nil
+}
+
+// sendVerification mails the user a new verification token, linked to
+// APP_URL when it is set.
+func (u userUsecase) sendVerification(ctx context.Context, user *domain.User) error {
+	token, tokenHash, err := domain.NewVerificationToken()
+
+	if err != nil {
+		return err
+	}
+
+	_, err = u.verificationRepo.Create(ctx, &domain.EmailVerification{
+		UserId:    user.ID.Hex(),
+		Email:     user.Email,
+		TokenHash: tokenHash,
+		ExpiresAt: time.Now().UTC().Add(domain.EmailVerificationTTL()),
+	})
+
+	if err != nil {
+		return err
+	}
+
+	verify := "Your verification token is " + token
+	if appUrl := os.Getenv("APP_URL"); appUrl != "" {
+		verify = "Verify your email at " + strings.TrimRight(appUrl, "/") + "/verify-email?token=" + url.QueryEscape(token)
+	}
+
+	return u.mailer.Send(ctx, domain.Message{
+		To:      user.Email,
+		Subject: "Verify your email",
+		Body: fmt.Sprintf("Hi %s,\n\n%s\n\nThe token expires in %s.\n",
+			user.Firstname, verify, domain.EmailVerificationTTL()),
+	})
+}
+
+func (u userUsecase) IssueTokens(ctx context.Context, user *domain.User) (*domain.TokenPair, error) {
+	return u.issueTokens(ctx, user, primitive.NewObjectID().Hex())
+}
+
+func (u userUsecase) RefreshTokens(ctx context.Context, refreshToken string) (*domain.TokenPair, error) {
+	token, err := u.refreshTokenRepo.GetByHash(ctx, domain.HashRefreshToken(refreshToken))
+
+	if err != nil || token.RevokedAt != nil || time.Now().After(token.ExpiresAt) {
+		return nil, domain.ErrInvalidRefreshToken
+	}
+
+	// a rotated token coming back means it leaked, so the whole family goes
+	fresh, err := u.refreshTokenRepo.MarkUsed(ctx, token)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if !fresh {
+		if err = u.refreshTokenRepo.RevokeFamily(ctx, token.FamilyId); err != nil {
+			return nil, err
+		}
+		return nil, domain.ErrRefreshTokenReused
+	}
+
+	user, err := u.userRepo.GetById(ctx, token.UserId)
+
+	if err != nil {
+		return nil, domain.ErrInvalidRefreshToken
+	}
+
+	if user.BannedAt != nil {
+		return nil, errors.New("account banned")
+	}
+
+	return u.issueTokens(ctx, user, token.FamilyId)
+}
+
+func (u userUsecase) Logout(ctx context.Context, userId, jti string, expiresAt time.Time, refreshToken string) error {
+	// tokens issued before jti claims can only be revoked with the user
+	if jti != "" {
+		if err := u.revocationStore.RevokeToken(ctx, jti, expiresAt); err != nil {
+			return err
+		}
+	}
+
+	if refreshToken == "" {
+		return nil
+	}
+
+	token, err := u.refreshTokenRepo.GetByHash(ctx, domain.HashRefreshToken(refreshToken))
+
+	if err != nil || token.UserId != userId {
+		return domain.ErrInvalidRefreshToken
+	}
+
+	return u.refreshTokenRepo.RevokeFamily(ctx, token.FamilyId)
+}
+
+func (u userUsecase) RevokeSessions(ctx context.Context, userId string) error {
+	if _, err := u.userRepo.GetById(ctx, userId); err != nil {
+		return err
+	}
+
+	if err := u.revocationStore.RevokeUser(ctx, userId); err != nil {
+		return err
+	}
+
+	return u.refreshTokenRepo.RevokeUser(ctx, userId)
+}
+
+func (u userUsecase) issueTokens(ctx context.Context, user *domain.User, familyId string) (*domain.TokenPair, error) {
+	accessToken, err := domain.GenerateToken(u.keys, *user)
+
+	if err != nil {
+		return nil, err
+	}
+
+	refreshToken, tokenHash, err := domain.NewRefreshToken()
+
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = u.refreshTokenRepo.Create(ctx, &domain.RefreshToken{
+		UserId:    user.ID.Hex(),
+		FamilyId:  familyId,
+		TokenHash: tokenHash,
+		ExpiresAt: time.Now().UTC().Add(domain.RefreshTokenTTL()),
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &domain.TokenPair{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+		ExpiresIn:    int64(domain.AccessTokenTTL().Seconds()),
+	}, nil
+}
+
+func New(u domain.UserRepository, refreshTokenRepo domain.RefreshTokenRepository, revocationStore domain.RevocationStore, keys *domain.KeySet, verificationRepo domain.EmailVerificationRepository, mailer domain.Mailer) domain.UserUsecase {
 	return &userUsecase{
-		userRepo: u,
+		userRepo:         u,
+		refreshTokenRepo: refreshTokenRepo,
+		revocationStore:  revocationStore,
+		keys:             keys,
+		verificationRepo: verificationRepo,
+		mailer:           mailer,
 	}
 }
@@ -1,15 +1,27 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"fmt"
 	"log"
+	commentU "movies-review-api/application/comment"
+	filmU "movies-review-api/application/film"
 	httpDelivery "movies-review-api/delivery/http"
 	port "movies-review-api/delivery/http"
+	"movies-review-api/delivery/worker"
 	"movies-review-api/domain"
 	"movies-review-api/pkg/logger"
+	"movies-review-api/pkg/mailer"
+	"movies-review-api/repository/memory"
 	"movies-review-api/repository/mongodb"
+	"movies-review-api/repository/swapi"
 	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+
+	"go.uber.org/zap"
 )
 
 func init() {
@@ -33,16 +45,83 @@ func main() {
 
 	domain.GetSecrets(l)
 
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	keys, err := domain.LoadKeySet()
+	if err != nil {
+		l.Fatal("error occurred while loading the jwt signing keys", zap.Error(err))
+	}
+
 	repo := mongodb.New(l)
 
+	// promote the configured admins so roles can be managed from the api
+	bootstrapAdmins(ctx, l, repo.UserRepo, os.Getenv("ADMIN_EMAILS"))
+
+	// revoked tokens are shared through mongo unless a single instance
+	// keeps them in memory
+	revocationStore := repo.RevocationStore
+	if os.Getenv("REVOCATION_STORE") == "memory" {
+		revocationStore = memory.NewRevocationStore()
+	}
+
+	filmSources := domain.NewFilmSourceRegistry(
+		swapi.NewFilmSource(os.Getenv("SWAPI_BASE_URL")),
+	)
+
+	// sync films in the background so requests only read from the database
+	filmSyncWorker := worker.NewFilmSyncWorker(l, filmU.New(repo.FilmRepo, repo.SyncRunRepo, filmSources))
+	workerDone := make(chan struct{})
+	go func() {
+		filmSyncWorker.Start(ctx)
+		close(workerDone)
+	}()
+
+	emailMailer, err := mailer.New(l)
+	if err != nil {
+		l.Fatal("error occurred while loading the mailer", zap.Error(err))
+	}
+
+	commentFilters, err := commentU.DefaultModerationFilters(repo.CommentRepo)
+	if err != nil {
+		l.Fatal("error occurred while loading the comment moderation filters", zap.Error(err))
+	}
+
 	httpConfig := httpDelivery.Config{
-		UserRepo:    repo.UserRepo,
-		FilmRepo:    repo.FilmRepo,
-		CommentRepo: repo.CommentRepo,
+		UserRepo:      repo.UserRepo,
+		FilmRepo:      repo.FilmRepo,
+		CommentRepo:   repo.CommentRepo,
+		SyncRunRepo:   repo.SyncRunRepo,
+		CharacterRepo: repo.CharacterRepo,
+		PlanetRepo:    repo.PlanetRepo,
+		StarshipRepo:  repo.StarshipRepo,
+		VehicleRepo:   repo.VehicleRepo,
+		SpeciesRepo:   repo.SpeciesRepo,
+
+		CommentRevisionRepo:   repo.CommentRevisionRepo,
+		ReactionRepo:          repo.ReactionRepo,
+		RatingRepo:            repo.RatingRepo,
+		ReviewRepo:            repo.ReviewRepo,
+		ReviewVoteRepo:        repo.ReviewVoteRepo,
+		CommentReportRepo:     repo.CommentReportRepo,
+		ModerationRepo:        repo.ModerationRepo,
+		RefreshTokenRepo:      repo.RefreshTokenRepo,
+		RevocationStore:       revocationStore,
+		KeySet:                keys,
+		EmailVerificationRepo: repo.EmailVerificationRepo,
+		Mailer:                emailMailer,
+		CommentFilters:        commentFilters,
 	}
 
 	app := port.RunHttpServer(httpConfig)
 
+	go func() {
+		<-ctx.Done()
+		if err := app.Shutdown(); err != nil {
+			l.Error(err.Error())
+		}
+	}()
+
 	port := os.Getenv("PORT")
 
 	if port == "" {
@@ -51,5 +130,35 @@ func main() {
 
 	addr := flag.String("addr", fmt.Sprintf(":%s", port), "http service address")
 	flag.Parse()
-	log.Fatal(app.Listen(*addr))
+	if err := app.Listen(*addr); err != nil {
+		log.Fatal(err)
+	}
+
+	stop()
+	<-workerDone
+}
+
+// bootstrapAdmins gives the admin role to the users with the comma
+// separated emails.
+func bootstrapAdmins(ctx context.Context, l *zap.Logger, userRepo domain.UserRepository, emails string) {
+	for _, email := range strings.Split(emails, ",") {
+		// stored emails are lowercase, as signup lowercases them
+		if email = strings.ToLower(strings.TrimSpace(email)); email == "" {
+			continue
+		}
+
+		user, err := userRepo.GetByEmail(ctx, email)
+		if err != nil {
+			l.Warn("admin user not found", zap.String("email", email), zap.Error(err))
+			continue
+		}
+
+		if user.Role == domain.RoleAdmin {
+			continue
+		}
+
+		if _, err = userRepo.SetRole(ctx, user.ID.Hex(), domain.RoleAdmin); err != nil {
+			l.Error("error occurred while promoting admin user", zap.String("email", email), zap.Error(err))
+		}
+	}
 }
@@ -0,0 +1,136 @@
+package admin
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/go-playground/validator/v10"
+	"movies-review-api/delivery/http/middleware"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+	"movies-review-api/domain"
+	"movies-review-api/pkg/logger"
+)
+
+var (
+	validate = validator.New()
+)
+
+type AdminHandler struct {
+	SyncRunRepo domain.SyncRunRepository
+	FilmRepo    domain.FilmRepository
+	UserRepo    domain.UserRepository
+	UserUsecase domain.UserUsecase
+	Logger      *zap.Logger
+}
+
+type anonymousCommentsRequest struct {
+	Enabled bool `json:"enabled"`
+}
+
+func New(adminRouter fiber.Router, syncRunRepo domain.SyncRunRepository, filmRepo domain.FilmRepository, userRepo domain.UserRepository, keys *domain.KeySet, revocationStore domain.RevocationStore, userUsecase domain.UserUsecase) {
+	handler := &AdminHandler{
+		SyncRunRepo: syncRunRepo,
+		FilmRepo:    filmRepo,
+		UserRepo:    userRepo,
+		UserUsecase: userUsecase,
+	}
+
+	l, _ := logger.InitLogger()
+
+	handler.Logger = l
+
+	// every admin route requires an admin, including the ones added later
+	adminRouter.Use(middleware.Protected(userRepo, keys, revocationStore), middleware.RequireRole(domain.RoleAdmin))
+
+	adminRouter.Get("/sync/runs", handler.FetchPaginatedSyncRuns)
+	adminRouter.Put("/films/:id/anonymous-comments", handler.SetAnonymousComments)
+	adminRouter.Put("/users/:id/role", handler.SetUserRole)
+	adminRouter.Post("/users/:id/revoke-sessions", handler.RevokeUserSessions)
+}
+
+func (h *AdminHandler) FetchPaginatedSyncRuns(c *fiber.Ctx) error {
+	page := c.Query("page", "1")
+
+	pageInt, err := strconv.Atoi(page)
+	if err != nil {
+		return domain.HandleError(c, err)
+	}
+
+	limit := c.Query("limit", "20")
+
+	limitInt, err := strconv.Atoi(limit)
+	if err != nil {
+		return domain.HandleError(c, err)
+	}
+
+	data, err := h.SyncRunRepo.FetchPaginatedSyncRuns(context.TODO(), int64(pageInt), int64(limitInt))
+
+	if err != nil {
+		return domain.HandleError(c, err)
+	}
+
+	return c.JSON(fiber.Map{
+		"error": false,
+		"data":  data,
+	})
+}
+
+func (h *AdminHandler) SetAnonymousComments(c *fiber.Ctx) error {
+	var data anonymousCommentsRequest
+
+	if err := json.Unmarshal(c.Body(), &data); err != nil {
+		return domain.HandleError(c, err)
+	}
+
+	film, err := h.FilmRepo.SetAllowAnonymousComments(context.TODO(), c.Params("id"), data.Enabled)
+
+	if err != nil {
+		return domain.HandleError(c, err)
+	}
+
+	return c.JSON(fiber.Map{
+		"error": false,
+		"data":  film,
+	})
+}
+
+func (h *AdminHandler) SetUserRole(c *fiber.Ctx) error {
+	var data domain.SetRoleRequest
+
+	if err := json.Unmarshal(c.Body(), &data); err != nil {
+		return domain.HandleError(c, err)
+	}
+
+	if err := validate.Struct(data); err != nil {
+		return domain.HandleValidationError(c, err)
+	}
+
+	user, err := h.UserRepo.SetRole(context.TODO(), c.Params("id"), data.Role)
+
+	if err != nil {
+		return domain.HandleError(c, err)
+	}
+
+	user.Password = ""
+
+	return c.JSON(fiber.Map{
+		"error": false,
+		"data":  user,
+	})
+}
+
+func (h *AdminHandler) RevokeUserSessions(c *fiber.Ctx) error {
+
+	err := h.UserUsecase.RevokeSessions(context.TODO(), c.Params("id"))
+
+	if err != nil {
+		return domain.HandleError(c, err)
+	}
+
+	return c.JSON(fiber.Map{
+		"error": false,
+		"data":  nil,
+	})
+}
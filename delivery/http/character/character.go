@@ -0,0 +1,73 @@
+package character
+
+import (
+	"context"
+	"movies-review-api/delivery/http/middleware"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+	"movies-review-api/domain"
+	"movies-review-api/pkg/logger"
+)
+
+type CharacterHandler struct {
+	CharacterRepo domain.CharacterRepository
+	Logger        *zap.Logger
+}
+
+func New(characterRouter fiber.Router, r domain.CharacterRepository, userRepo domain.UserRepository, keys *domain.KeySet, revocationStore domain.RevocationStore) {
+	handler := &CharacterHandler{
+		CharacterRepo: r,
+	}
+
+	l, _ := logger.InitLogger()
+
+	handler.Logger = l
+
+	characterRouter.Get("/", middleware.Protected(userRepo, keys, revocationStore), handler.FetchPaginatedCharacters)
+	characterRouter.Get("/:id", middleware.Protected(userRepo, keys, revocationStore), handler.FetchSingleCharacter)
+}
+
+func (h *CharacterHandler) FetchPaginatedCharacters(c *fiber.Ctx) error {
+	page := c.Query("page", "1")
+
+	pageInt, err := strconv.Atoi(page)
+	if err != nil {
+		return domain.HandleError(c, err)
+	}
+
+	limit := c.Query("limit", "20")
+
+	limitInt, err := strconv.Atoi(limit)
+	if err != nil {
+		return domain.HandleError(c, err)
+	}
+
+	data, err := h.CharacterRepo.FetchPaginatedCharacters(context.TODO(), int64(pageInt), int64(limitInt))
+
+	if err != nil {
+		return domain.HandleError(c, err)
+	}
+
+	return c.JSON(fiber.Map{
+		"error": false,
+		"data":  data,
+	})
+}
+
+func (h *CharacterHandler) FetchSingleCharacter(c *fiber.Ctx) error {
+
+	id := c.Params("id")
+
+	data, err := h.CharacterRepo.GetById(context.TODO(), id)
+
+	if err != nil {
+		return domain.HandleError(c, err)
+	}
+
+	return c.JSON(fiber.Map{
+		"error": false,
+		"data":  data,
+	})
+}
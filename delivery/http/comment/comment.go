@@ -3,9 +3,11 @@ package comment
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"github.com/go-playground/validator/v10"
 	"movies-review-api/delivery/http/middleware"
 	"strconv"
+	"strings"
 
 	"github.com/gofiber/fiber/v2"
 	"go.uber.org/zap"
@@ -18,23 +20,39 @@ var (
 )
 
 type CommentHandler struct {
-	CommentRepo    domain.CommentRepository
-	CommentUsecase domain.CommentUsecase
-	Logger         *zap.Logger
+	CommentRepo       domain.CommentRepository
+	CommentUsecase    domain.CommentUsecase
+	ModerationUsecase domain.ModerationUsecase
+	Logger            *zap.Logger
 }
 
-func New(commentRouter fiber.Router, r domain.CommentRepository, userRepo domain.UserRepository, commentUsecase domain.CommentUsecase) {
+func New(commentRouter fiber.Router, r domain.CommentRepository, userRepo domain.UserRepository, keys *domain.KeySet, revocationStore domain.RevocationStore, commentUsecase domain.CommentUsecase, moderationUsecase domain.ModerationUsecase) {
 	handler := &CommentHandler{
-		CommentRepo:    r,
-		CommentUsecase: commentUsecase,
+		CommentRepo:       r,
+		CommentUsecase:    commentUsecase,
+		ModerationUsecase: moderationUsecase,
 	}
 
 	l, _ := logger.InitLogger()
 
 	handler.Logger = l
 
-	commentRouter.Post("/", middleware.Protected(userRepo), handler.AddComment)
-	commentRouter.Get("/:filmId", middleware.Protected(userRepo), handler.FetchPostComments)
+	commentRouter.Post("/", middleware.OptionalAuth(userRepo, keys, revocationStore), handler.AddComment)
+	commentRouter.Get("/moderation/held", middleware.Protected(userRepo, keys, revocationStore), middleware.RequireRole(domain.RoleModerator), handler.FetchHeldComments)
+	commentRouter.Get("/moderation/reports", middleware.Protected(userRepo, keys, revocationStore), middleware.RequireRole(domain.RoleModerator), handler.FetchReportedComments)
+	commentRouter.Get("/:filmId", middleware.OptionalAuth(userRepo, keys, revocationStore), handler.FetchPostComments)
+	commentRouter.Patch("/:id", middleware.Protected(userRepo, keys, revocationStore), handler.UpdateComment)
+	commentRouter.Delete("/:id", middleware.Protected(userRepo, keys, revocationStore), handler.DeleteComment)
+	commentRouter.Get("/:id/revisions", middleware.Protected(userRepo, keys, revocationStore), handler.FetchCommentRevisions)
+	commentRouter.Get("/:id/replies", middleware.OptionalAuth(userRepo, keys, revocationStore), handler.FetchCommentReplies)
+	commentRouter.Get("/:id/reactions", middleware.OptionalAuth(userRepo, keys, revocationStore), handler.FetchCommentReactions)
+	commentRouter.Put("/:id/reactions/:type", middleware.Protected(userRepo, keys, revocationStore), handler.AddReaction)
+	commentRouter.Delete("/:id/reactions/:type", middleware.Protected(userRepo, keys, revocationStore), handler.RemoveReaction)
+	commentRouter.Post("/:id/approve", middleware.Protected(userRepo, keys, revocationStore), middleware.RequireRole(domain.RoleModerator), handler.ApproveComment)
+	commentRouter.Post("/:id/reject", middleware.Protected(userRepo, keys, revocationStore), middleware.RequireRole(domain.RoleModerator), handler.RejectComment)
+	commentRouter.Post("/:id/report", middleware.Protected(userRepo, keys, revocationStore), handler.ReportComment)
+	commentRouter.Post("/:id/moderation", middleware.Protected(userRepo, keys, revocationStore), middleware.RequireRole(domain.RoleModerator), handler.ModerateComment)
+	commentRouter.Get("/:id/moderation", middleware.Protected(userRepo, keys, revocationStore), middleware.RequireRole(domain.RoleModerator), handler.FetchModerationActions)
 }
 
 func (h *CommentHandler) AddComment(c *fiber.Ctx) error {
@@ -52,17 +70,22 @@ func (h *CommentHandler) AddComment(c *fiber.Ctx) error {
 		return domain.HandleValidationError(c, err)
 	}
 
-	data.UserId = c.Locals("user_id").(string)
+	// the user_id is unset for anonymous comments
+	data.UserId, _ = c.Locals("user_id").(string)
+	data.AuthorIp = c.IP()
+	if principal, ok := c.Locals("principal").(*domain.Principal); ok {
+		data.EmailVerified = principal.EmailVerified
+	}
 
 	comment, err := h.CommentUsecase.AddComment(context.TODO(), &data)
 
 	if err != nil {
-		return domain.HandleError(c, err)
+		return handleCommentError(c, err)
 	}
 
 	return c.JSON(fiber.Map{
 		"error": false,
-		"data":  comment,
+		"data":  presentComment(c, *comment),
 	})
 }
 
@@ -84,14 +107,393 @@ func (h *CommentHandler) FetchPostComments(c *fiber.Ctx) error {
 		return domain.HandleError(c, err)
 	}
 
-	data, err := h.CommentRepo.FetchPaginatedFilmComments(context.TODO(), filmId, int64(pageInt), int64(limitInt))
+	cursor, err := domain.DecodeCursor(c.Query("cursor"))
+	if err != nil {
+		return domain.HandleFieldErrors(c, domain.FieldErrors{"cursor": err.Error()})
+	}
+
+	sort := strings.ToLower(c.Query("sort", domain.CommentSortOldest))
+	if sort != domain.CommentSortOldest && sort != domain.CommentSortTop {
+		return domain.HandleFieldErrors(c, domain.FieldErrors{"sort": "must be one of [oldest top]"})
+	}
+
+	// a cursor parameter, even an empty one, switches to cursor pagination
+	useCursor := c.Context().QueryArgs().Has("cursor")
+
+	if useCursor && sort != domain.CommentSortOldest {
+		return domain.HandleFieldErrors(c, domain.FieldErrors{"sort": "cannot be used with cursor"})
+	}
+
+	var data *domain.PaginatedComment
+
+	if useCursor {
+		data, err = h.CommentRepo.FetchCursorFilmComments(context.TODO(), filmId, cursor, int64(limitInt))
+	} else {
+		data, err = h.CommentRepo.FetchPaginatedFilmComments(context.TODO(), filmId, sort, int64(pageInt), int64(limitInt))
+	}
+
+	if err != nil {
+		return domain.HandleError(c, err)
+	}
+
+	data.Data = presentComments(c, data.Data)
+
+	return c.JSON(fiber.Map{
+		"error": false,
+		"data":  data,
+	})
+}
+
+func (h *CommentHandler) UpdateComment(c *fiber.Ctx) error {
+	var data domain.UpdateCommentRequest
+
+	if err := json.Unmarshal(c.Body(), &data); err != nil {
+		return domain.HandleError(c, err)
+	}
+
+	if err := validate.Struct(data); err != nil {
+		return domain.HandleValidationError(c, err)
+	}
+
+	comment, err := h.CommentUsecase.UpdateComment(context.TODO(), c.Params("id"), c.Locals("user_id").(string), &data)
+
+	if err != nil {
+		return handleCommentError(c, err)
+	}
+
+	return c.JSON(fiber.Map{
+		"error": false,
+		"data":  presentComment(c, *comment),
+	})
+}
+
+func (h *CommentHandler) DeleteComment(c *fiber.Ctx) error {
+
+	err := h.CommentUsecase.DeleteComment(context.TODO(), c.Params("id"), c.Locals("user_id").(string))
+
+	if err != nil {
+		return handleCommentError(c, err)
+	}
+
+	return c.JSON(fiber.Map{
+		"error": false,
+		"data":  nil,
+	})
+}
+
+func (h *CommentHandler) FetchCommentRevisions(c *fiber.Ctx) error {
+
+	page := c.Query("page", "1")
+
+	pageInt, err := strconv.Atoi(page)
+	if err != nil {
+		return domain.HandleError(c, err)
+	}
+
+	limit := c.Query("limit", "20")
 
+	limitInt, err := strconv.Atoi(limit)
 	if err != nil {
 		return domain.HandleError(c, err)
 	}
 
+	data, err := h.CommentUsecase.FetchCommentRevisions(context.TODO(), c.Params("id"), c.Locals("user_id").(string), c.Locals("role").(string), int64(pageInt), int64(limitInt))
+
+	if err != nil {
+		return handleCommentError(c, err)
+	}
+
 	return c.JSON(fiber.Map{
 		"error": false,
 		"data":  data,
 	})
 }
+
+func (h *CommentHandler) FetchCommentReplies(c *fiber.Ctx) error {
+
+	page := c.Query("page", "1")
+
+	pageInt, err := strconv.Atoi(page)
+	if err != nil {
+		return domain.HandleError(c, err)
+	}
+
+	limit := c.Query("limit", "20")
+
+	limitInt, err := strconv.Atoi(limit)
+	if err != nil {
+		return domain.HandleError(c, err)
+	}
+
+	data, err := h.CommentUsecase.FetchCommentReplies(context.TODO(), c.Params("id"), int64(pageInt), int64(limitInt))
+
+	if err != nil {
+		return domain.HandleError(c, err)
+	}
+
+	data.Data = presentComments(c, data.Data)
+
+	return c.JSON(fiber.Map{
+		"error": false,
+		"data":  data,
+	})
+}
+
+func (h *CommentHandler) FetchCommentReactions(c *fiber.Ctx) error {
+
+	page := c.Query("page", "1")
+
+	pageInt, err := strconv.Atoi(page)
+	if err != nil {
+		return domain.HandleError(c, err)
+	}
+
+	limit := c.Query("limit", "20")
+
+	limitInt, err := strconv.Atoi(limit)
+	if err != nil {
+		return domain.HandleError(c, err)
+	}
+
+	data, err := h.CommentUsecase.FetchCommentReactions(context.TODO(), c.Params("id"), strings.ToLower(c.Query("type")), int64(pageInt), int64(limitInt))
+
+	if err != nil {
+		return domain.HandleError(c, err)
+	}
+
+	return c.JSON(fiber.Map{
+		"error": false,
+		"data":  data,
+	})
+}
+
+func (h *CommentHandler) AddReaction(c *fiber.Ctx) error {
+
+	reaction, err := h.CommentUsecase.AddReaction(context.TODO(), c.Params("id"), c.Locals("user_id").(string), strings.ToLower(c.Params("type")))
+
+	if err != nil {
+		return domain.HandleError(c, err)
+	}
+
+	return c.JSON(fiber.Map{
+		"error": false,
+		"data":  reaction,
+	})
+}
+
+func (h *CommentHandler) RemoveReaction(c *fiber.Ctx) error {
+
+	err := h.CommentUsecase.RemoveReaction(context.TODO(), c.Params("id"), c.Locals("user_id").(string), strings.ToLower(c.Params("type")))
+
+	if err != nil {
+		return domain.HandleError(c, err)
+	}
+
+	return c.JSON(fiber.Map{
+		"error": false,
+		"data":  nil,
+	})
+}
+
+func (h *CommentHandler) FetchHeldComments(c *fiber.Ctx) error {
+
+	page := c.Query("page", "1")
+
+	pageInt, err := strconv.Atoi(page)
+	if err != nil {
+		return domain.HandleError(c, err)
+	}
+
+	limit := c.Query("limit", "20")
+
+	limitInt, err := strconv.Atoi(limit)
+	if err != nil {
+		return domain.HandleError(c, err)
+	}
+
+	data, err := h.ModerationUsecase.FetchHeldComments(context.TODO(), int64(pageInt), int64(limitInt))
+
+	if err != nil {
+		return handleCommentError(c, err)
+	}
+
+	return c.JSON(fiber.Map{
+		"error": false,
+		"data":  data,
+	})
+}
+
+func (h *CommentHandler) ApproveComment(c *fiber.Ctx) error {
+
+	comment, err := h.ModerationUsecase.ApproveComment(context.TODO(), c.Params("id"), c.Locals("user_id").(string))
+
+	if err != nil {
+		return handleCommentError(c, err)
+	}
+
+	return c.JSON(fiber.Map{
+		"error": false,
+		"data":  comment,
+	})
+}
+
+func (h *CommentHandler) RejectComment(c *fiber.Ctx) error {
+
+	err := h.ModerationUsecase.RejectComment(context.TODO(), c.Params("id"), c.Locals("user_id").(string))
+
+	if err != nil {
+		return handleCommentError(c, err)
+	}
+
+	return c.JSON(fiber.Map{
+		"error": false,
+		"data":  nil,
+	})
+}
+
+func (h *CommentHandler) FetchReportedComments(c *fiber.Ctx) error {
+
+	page := c.Query("page", "1")
+
+	pageInt, err := strconv.Atoi(page)
+	if err != nil {
+		return domain.HandleError(c, err)
+	}
+
+	limit := c.Query("limit", "20")
+
+	limitInt, err := strconv.Atoi(limit)
+	if err != nil {
+		return domain.HandleError(c, err)
+	}
+
+	data, err := h.ModerationUsecase.FetchReportedComments(context.TODO(), int64(pageInt), int64(limitInt))
+
+	if err != nil {
+		return handleCommentError(c, err)
+	}
+
+	return c.JSON(fiber.Map{
+		"error": false,
+		"data":  data,
+	})
+}
+
+func (h *CommentHandler) ReportComment(c *fiber.Ctx) error {
+	var data domain.ReportCommentRequest
+
+	if err := json.Unmarshal(c.Body(), &data); err != nil {
+		return domain.HandleError(c, err)
+	}
+
+	if err := validate.Struct(data); err != nil {
+		return domain.HandleValidationError(c, err)
+	}
+
+	report, err := h.ModerationUsecase.ReportComment(context.TODO(), c.Params("id"), c.Locals("user_id").(string), &data)
+
+	if err != nil {
+		return handleCommentError(c, err)
+	}
+
+	return c.JSON(fiber.Map{
+		"error": false,
+		"data":  report,
+	})
+}
+
+func (h *CommentHandler) ModerateComment(c *fiber.Ctx) error {
+	var data domain.ModerateCommentRequest
+
+	if err := json.Unmarshal(c.Body(), &data); err != nil {
+		return domain.HandleError(c, err)
+	}
+
+	if err := validate.Struct(data); err != nil {
+		return domain.HandleValidationError(c, err)
+	}
+
+	comment, err := h.ModerationUsecase.ModerateComment(context.TODO(), c.Params("id"), c.Locals("user_id").(string), &data)
+
+	if err != nil {
+		return handleCommentError(c, err)
+	}
+
+	return c.JSON(fiber.Map{
+		"error": false,
+		"data":  comment,
+	})
+}
+
+func (h *CommentHandler) FetchModerationActions(c *fiber.Ctx) error {
+
+	page := c.Query("page", "1")
+
+	pageInt, err := strconv.Atoi(page)
+	if err != nil {
+		return domain.HandleError(c, err)
+	}
+
+	limit := c.Query("limit", "20")
+
+	limitInt, err := strconv.Atoi(limit)
+	if err != nil {
+		return domain.HandleError(c, err)
+	}
+
+	data, err := h.ModerationUsecase.FetchModerationActions(context.TODO(), c.Params("id"), int64(pageInt), int64(limitInt))
+
+	if err != nil {
+		return handleCommentError(c, err)
+	}
+
+	return c.JSON(fiber.Map{
+		"error": false,
+		"data":  data,
+	})
+}
+
+// presentComment keeps the full author ip for moderators and masks it for
+// everyone else.
+func presentComment(c *fiber.Ctx, comment domain.Comment) domain.Comment {
+	role, _ := c.Locals("role").(string)
+	if domain.IsModerator(role) {
+		return comment
+	}
+	return comment.Public()
+}
+
+func presentComments(c *fiber.Ctx, comments []domain.Comment) []domain.Comment {
+	for i := range comments {
+		comments[i] = presentComment(c, comments[i])
+	}
+	return comments
+}
+
+func handleCommentError(c *fiber.Ctx, err error) error {
+	if errors.Is(err, domain.ErrAnonymousCommentsDisabled) {
+		return c.Status(fiber.StatusUnauthorized).JSON(
+			fiber.Map{
+				"error": true,
+				"msg":   err.Error(),
+			})
+	}
+
+	if errors.Is(err, domain.ErrCommentRejected) {
+		return c.Status(fiber.StatusUnprocessableEntity).JSON(
+			fiber.Map{
+				"error": true,
+				"msg":   err.Error(),
+			})
+	}
+
+	if errors.Is(err, domain.ErrCommentForbidden) || errors.Is(err, domain.ErrEmailNotVerified) {
+		return c.Status(fiber.StatusForbidden).JSON(
+			fiber.Map{
+				"error": true,
+				"msg":   err.Error(),
+			})
+	}
+
+	return domain.HandleError(c, err)
+}
@@ -2,8 +2,12 @@ package film
 
 import (
 	"context"
+	"encoding/json"
+	"github.com/go-playground/validator/v10"
 	"movies-review-api/delivery/http/middleware"
+	"reflect"
 	"strconv"
+	"strings"
 
 	"github.com/gofiber/fiber/v2"
 	"go.uber.org/zap"
@@ -11,24 +15,45 @@ import (
 	"movies-review-api/pkg/logger"
 )
 
+var (
+	validate = validator.New()
+)
+
+func init() {
+	// report invalid fields by their json name
+	validate.RegisterTagNameFunc(func(field reflect.StructField) string {
+		name := strings.SplitN(field.Tag.Get("json"), ",", 2)[0]
+		if name == "-" {
+			return ""
+		}
+		return name
+	})
+}
+
 type FilmHandler struct {
-	FilmRepo    domain.FilmRepository
-	FilmUsecase domain.FilmUsecase
-	Logger      *zap.Logger
+	FilmRepo      domain.FilmRepository
+	CharacterRepo domain.CharacterRepository
+	RatingUsecase domain.RatingUsecase
+	Logger        *zap.Logger
 }
 
-func New(filmRouter fiber.Router, r domain.FilmRepository, userRepo domain.UserRepository, filmUsecase domain.FilmUsecase) {
+func New(filmRouter fiber.Router, r domain.FilmRepository, userRepo domain.UserRepository, keys *domain.KeySet, revocationStore domain.RevocationStore, characterRepo domain.CharacterRepository, ratingUsecase domain.RatingUsecase) {
 	handler := &FilmHandler{
-		FilmRepo:    r,
-		FilmUsecase: filmUsecase,
+		FilmRepo:      r,
+		CharacterRepo: characterRepo,
+		RatingUsecase: ratingUsecase,
 	}
 
 	l, _ := logger.InitLogger()
 
 	handler.Logger = l
 
-	filmRouter.Get("/", middleware.Protected(userRepo), handler.FetchPaginatedFilms)
-	filmRouter.Get("/:id", middleware.Protected(userRepo), handler.FetchSingleFilm)
+	filmRouter.Get("/", middleware.Protected(userRepo, keys, revocationStore), handler.FetchPaginatedFilms)
+	filmRouter.Get("/:id", middleware.Protected(userRepo, keys, revocationStore), handler.FetchSingleFilm)
+	filmRouter.Get("/:id/characters", middleware.Protected(userRepo, keys, revocationStore), handler.FetchFilmCharacters)
+	filmRouter.Get("/:id/rating", middleware.Protected(userRepo, keys, revocationStore), handler.FetchUserRating)
+	filmRouter.Put("/:id/rating", middleware.Protected(userRepo, keys, revocationStore), handler.RateFilm)
+	filmRouter.Delete("/:id/rating", middleware.Protected(userRepo, keys, revocationStore), handler.DeleteRating)
 }
 
 func (h *FilmHandler) FetchPaginatedFilms(c *fiber.Ctx) error {
@@ -46,12 +71,74 @@ func (h *FilmHandler) FetchPaginatedFilms(c *fiber.Ctx) error {
 		return domain.HandleError(c, err)
 	}
 
-	data, err := h.FilmUsecase.FetchFilmsFromAllSources(context.TODO(), int64(pageInt), int64(limitInt))
+	var query domain.FilmQuery
+	if err := c.QueryParser(&query); err != nil {
+		return domain.HandleError(c, err)
+	}
+	query.Sort = strings.ToLower(query.Sort)
+	query.Order = strings.ToLower(query.Order)
+
+	errs := query.Validate(validate)
+	if errs == nil {
+		errs = domain.FieldErrors{}
+	}
+
+	fields, err := domain.ParseFilmFields(c.Query("fields"))
+	if err != nil {
+		errs["fields"] = err.Error()
+	}
+	query.Fields = fields
+
+	// a cursor parameter, even an empty one, switches to cursor pagination
+	useCursor := c.Context().QueryArgs().Has("cursor")
+
+	cursor, err := domain.DecodeCursor(c.Query("cursor"))
+	if err != nil {
+		errs["cursor"] = err.Error()
+	}
+	if useCursor && (query.Sort != "" || query.Order != "") {
+		errs["sort"] = "cannot be used with cursor"
+	}
+
+	if len(errs) > 0 {
+		return domain.HandleFieldErrors(c, errs)
+	}
+
+	var data *domain.PaginatedFilm
+	if useCursor {
+		data, err = h.FilmRepo.FetchCursorFilms(context.TODO(), query, cursor, int64(limitInt))
+	} else {
+		data, err = h.FilmRepo.FetchPaginatedFilms(context.TODO(), query, int64(pageInt), int64(limitInt))
+	}
 
 	if err != nil {
 		return domain.HandleError(c, err)
 	}
 
+	if len(fields) > 0 {
+		projected := make([]map[string]interface{}, 0, len(data.Data))
+		for _, film := range data.Data {
+			p, err := film.Project(fields)
+			if err != nil {
+				return domain.HandleError(c, err)
+			}
+			projected = append(projected, p)
+		}
+
+		paginated := fiber.Map{"data": projected}
+		if data.Pagination != nil {
+			paginated["pagination"] = data.Pagination
+		}
+		if data.Cursor != nil {
+			paginated["cursor"] = data.Cursor
+		}
+
+		return c.JSON(fiber.Map{
+			"error": false,
+			"data":  paginated,
+		})
+	}
+
 	return c.JSON(fiber.Map{
 		"error": false,
 		"data":  data,
@@ -73,3 +160,100 @@ func (h *FilmHandler) FetchSingleFilm(c *fiber.Ctx) error {
 		"data":  data,
 	})
 }
+
+func (h *FilmHandler) FetchFilmCharacters(c *fiber.Ctx) error {
+
+	id := c.Params("id")
+
+	page := c.Query("page", "1")
+
+	pageInt, err := strconv.Atoi(page)
+	if err != nil {
+		return domain.HandleError(c, err)
+	}
+
+	limit := c.Query("limit", "20")
+
+	limitInt, err := strconv.Atoi(limit)
+	if err != nil {
+		return domain.HandleError(c, err)
+	}
+
+	query := domain.FilmCharactersQuery{
+		Sort:   strings.ToLower(c.Query("sort", "name")),
+		Order:  strings.ToLower(c.Query("order", "asc")),
+		Gender: strings.ToLower(c.Query("gender")),
+	}
+
+	if err := validate.Struct(query); err != nil {
+		return domain.HandleValidationError(c, err)
+	}
+
+	film, err := h.FilmRepo.GetById(context.TODO(), id)
+
+	if err != nil {
+		return domain.HandleError(c, err)
+	}
+
+	data, err := h.CharacterRepo.FetchPaginatedFilmCharacters(context.TODO(), film.CharacterIds, query, int64(pageInt), int64(limitInt))
+
+	if err != nil {
+		return domain.HandleError(c, err)
+	}
+
+	return c.JSON(fiber.Map{
+		"error": false,
+		"data":  data,
+	})
+}
+
+func (h *FilmHandler) FetchUserRating(c *fiber.Ctx) error {
+
+	rating, err := h.RatingUsecase.FetchUserRating(context.TODO(), c.Params("id"), c.Locals("user_id").(string))
+
+	if err != nil {
+		return domain.HandleError(c, err)
+	}
+
+	return c.JSON(fiber.Map{
+		"error": false,
+		"data":  rating,
+	})
+}
+
+func (h *FilmHandler) RateFilm(c *fiber.Ctx) error {
+	var data domain.RatingRequest
+
+	if err := json.Unmarshal(c.Body(), &data); err != nil {
+		return domain.HandleError(c, err)
+	}
+
+	if err := validate.Struct(data); err != nil {
+		return domain.HandleFieldErrors(c, domain.NewFieldErrors(err))
+	}
+
+	rating, err := h.RatingUsecase.RateFilm(context.TODO(), c.Params("id"), c.Locals("user_id").(string), &data)
+
+	if err != nil {
+		return domain.HandleError(c, err)
+	}
+
+	return c.JSON(fiber.Map{
+		"error": false,
+		"data":  rating,
+	})
+}
+
+func (h *FilmHandler) DeleteRating(c *fiber.Ctx) error {
+
+	err := h.RatingUsecase.DeleteRating(context.TODO(), c.Params("id"), c.Locals("user_id").(string))
+
+	if err != nil {
+		return domain.HandleError(c, err)
+	}
+
+	return c.JSON(fiber.Map{
+		"error": false,
+		"data":  nil,
+	})
+}
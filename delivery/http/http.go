@@ -21,3 +21,10 @@ func ping(c *fiber.Ctx) error {
 		Msg:   "pong",
 	})
 }
+
+func jwks(keys *domain.KeySet) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		c.Set(fiber.HeaderCacheControl, "public, max-age=300")
+		return c.JSON(keys.JWKS())
+	}
+}
@@ -1,16 +1,18 @@
 package middleware
 
 import (
-	"context"
+	"crypto/ecdsa"
+	"crypto/rsa"
 	"errors"
 	"fmt"
-	"os"
 
 	"github.com/dgrijalva/jwt-go"
 	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
 	"movies-review-api/domain"
-	"reflect"
+	"movies-review-api/pkg/logger"
 	"strings"
+	"time"
 )
 
 func jwtError(c *fiber.Ctx, err error) error {
@@ -24,12 +26,57 @@ func jwtError(c *fiber.Ctx, err error) error {
 	}
 }
 
-func Protected(userRepo domain.UserRepository) func(*fiber.Ctx) error {
-	return NewJwtHandler(domain.Config{
-		SigningKey:        []byte(os.Getenv("JWT_SECRET_KEY")),
+// Protected authenticates the request with an access token verified by the
+// keys and checked against the revocation store.
+func Protected(userRepo domain.UserRepository, keys *domain.KeySet, revocationStore domain.RevocationStore) func(*fiber.Ctx) error {
+	cfg := domain.Config{
 		ErrorHandler:      jwtError,
+		Issuer:            domain.TokenIssuer(),
+		Audience:          domain.TokenAudience(),
 		ValidatorFunction: userRepo,
-	})
+		RevocationStore:   revocationStore,
+	}
+	cfg.Logger, _ = logger.InitLogger()
+
+	// asymmetric keys are picked by kid, a secret is used as is
+	if verificationKeys := keys.VerificationKeys(); len(verificationKeys) > 0 {
+		cfg.SigningKeys = verificationKeys
+	} else {
+		cfg.SigningKey = keys.Active.Key
+		cfg.SigningMethod = keys.Active.Method.Alg()
+	}
+
+	return NewJwtHandler(cfg)
+}
+
+// OptionalAuth authenticates the request like Protected when it carries an
+// Authorization header and lets it through anonymously otherwise.
+func OptionalAuth(userRepo domain.UserRepository, keys *domain.KeySet, revocationStore domain.RevocationStore) func(*fiber.Ctx) error {
+	protected := Protected(userRepo, keys, revocationStore)
+
+	return func(c *fiber.Ctx) error {
+		if c.Get(fiber.HeaderAuthorization) == "" {
+			return c.Next()
+		}
+		return protected(c)
+	}
+}
+
+// RequireRole lets the request through when the user authenticated by
+// Protected holds one of the roles. Higher roles hold the rights of lower
+// ones, so RequireRole(domain.RoleModerator) lets admins through too.
+func RequireRole(roles ...string) func(*fiber.Ctx) error {
+	return func(c *fiber.Ctx) error {
+		role, _ := c.Locals("role").(string)
+
+		for _, required := range roles {
+			if role != "" && domain.HasRole(role, required) {
+				return c.Next()
+			}
+		}
+
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": true, "msg": "insufficient role", "data": nil})
+	}
 }
 
 func NewJwtHandler(config ...domain.Config) fiber.Handler {
@@ -62,8 +109,8 @@ func NewJwtHandler(config ...domain.Config) fiber.Handler {
 	if cfg.ContextKey == "" {
 		cfg.ContextKey = "token"
 	}
-	if cfg.Claims == nil {
-		cfg.Claims = jwt.MapClaims{}
+	if cfg.Logger == nil {
+		cfg.Logger = zap.NewNop()
 	}
 	if cfg.TokenLookup == "" {
 		cfg.TokenLookup = "header:" + fiber.HeaderAuthorization
@@ -73,18 +120,23 @@ func NewJwtHandler(config ...domain.Config) fiber.Handler {
 	}
 
 	cfg.KeyFunc = func(t *jwt.Token) (interface{}, error) {
-		// Check the signing method
-		if t.Method.Alg() != cfg.SigningMethod {
-			return nil, fmt.Errorf("unexpected jwt signing method=%v", t.Header["alg"])
-		}
 		if len(cfg.SigningKeys) > 0 {
 			if kid, ok := t.Header["kid"].(string); ok {
 				if key, ok := cfg.SigningKeys[kid]; ok {
+					// keys of retired algorithms are still accepted, so the
+					// signing method is checked against the key
+					if !keyMatchesMethod(key, t.Method) {
+						return nil, fmt.Errorf("unexpected jwt signing method=%v", t.Header["alg"])
+					}
 					return key, nil
 				}
 			}
 			return nil, fmt.Errorf("unexpected jwt key id=%v", t.Header["kid"])
 		}
+		// Check the signing method
+		if t.Method.Alg() != cfg.SigningMethod {
+			return nil, fmt.Errorf("unexpected jwt signing method=%v", t.Header["alg"])
+		}
 		return cfg.SigningKey, nil
 	}
 	// Initialize
@@ -123,40 +175,72 @@ func NewJwtHandler(config ...domain.Config) fiber.Handler {
 		if err != nil {
 			return cfg.ErrorHandler(c, err)
 		}
-		token := new(jwt.Token)
-
-		if _, ok := cfg.Claims.(jwt.MapClaims); ok {
-			token, err = jwt.Parse(auth, cfg.KeyFunc)
-		} else {
-			t := reflect.ValueOf(cfg.Claims).Type().Elem()
-			claims := reflect.New(t).Interface().(jwt.Claims)
-			token, err = jwt.ParseWithClaims(auth, claims, cfg.KeyFunc)
+		claims := &domain.AuthClaims{}
+		token, err := jwt.ParseWithClaims(auth, claims, cfg.KeyFunc)
+		if err != nil || !token.Valid {
+			return cfg.ErrorHandler(c, err)
+		}
+		if err = claims.Validate(cfg.Issuer, cfg.Audience); err != nil {
+			return cfg.ErrorHandler(c, err)
 		}
-		if err == nil && token.Valid {
-			// Store user information from token into context.
-			c.Locals(cfg.ContextKey, token)
-
-			// parse the claims type
 
-			if token.Claims.(jwt.MapClaims)["user"] != nil {
-				if token.Claims.(jwt.MapClaims)["user"].(map[string]interface{})["_id"] != nil {
-					userId := token.Claims.(jwt.MapClaims)["user"].(map[string]interface{})["_id"].(string)
-					_, err := cfg.ValidatorFunction.GetById(context.Background(), userId)
-					if err != nil {
-						return cfg.ErrorHandler(c, err)
-					}
-					c.Locals("user_id", userId)
-				}
+		user, err := cfg.ValidatorFunction.GetById(c.Context(), claims.Subject)
+		if err != nil {
+			return cfg.ErrorHandler(c, err)
+		}
+		if user.BannedAt != nil {
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": true, "msg": "account banned", "data": nil})
+		}
 
-				if token.Claims.(jwt.MapClaims)["user"].(map[string]interface{})["email"] != nil {
-					c.Locals("email", token.Claims.(jwt.MapClaims)["user"].(map[string]interface{})["email"])
-				}
+		if cfg.RevocationStore != nil {
+			revoked, err := cfg.RevocationStore.IsRevoked(c.Context(), claims.Id, claims.Subject, claims.IssuedTime())
+			// the token is refused when it cannot be checked
+			if err != nil {
+				cfg.Logger.Error("error occurred while checking token revocation", zap.Error(err))
+				return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{"error": true, "msg": "could not check the token", "data": nil})
 			}
+			if revoked {
+				return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": true, "msg": "token revoked", "data": nil})
+			}
+		}
+
+		// the stored role is used so role changes apply to issued tokens
+		role := user.Role
+		if role == "" {
+			role = domain.RoleUser
+		}
 
-			return cfg.SuccessHandler(c)
+		principal := &domain.Principal{
+			UserId:        claims.Subject,
+			Email:         user.Email,
+			Role:          role,
+			EmailVerified: user.IsEmailVerified(),
+			TokenId:       claims.Id,
+			ExpiresAt:     time.Unix(claims.ExpiresAt, 0),
 		}
-		return cfg.ErrorHandler(c, err)
+
+		// Store user information from token into context.
+		c.Locals(cfg.ContextKey, token)
+		c.Locals("principal", principal)
+		c.Locals("user_id", principal.UserId)
+		c.Locals("role", principal.Role)
+		c.Locals("email", principal.Email)
+		c.SetUserContext(domain.WithPrincipal(c.UserContext(), principal))
+
+		return cfg.SuccessHandler(c)
+	}
+}
+
+// keyMatchesMethod reports whether the key verifies tokens signed with the
+// method, so a public key is never used as an HMAC secret.
+func keyMatchesMethod(key interface{}, method jwt.SigningMethod) bool {
+	switch key.(type) {
+	case *rsa.PublicKey:
+		return method == jwt.SigningMethodRS256
+	case *ecdsa.PublicKey:
+		return method == jwt.SigningMethodES256
 	}
+	return false
 }
 
 // jwtFromHeader returns a function that extracts token from the request header.
@@ -0,0 +1,117 @@
+package middleware
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"errors"
+	"movies-review-api/domain"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestKeyMatchesMethod(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		key    interface{}
+		method jwt.SigningMethod
+		want   bool
+	}{
+		{name: "rsa RS256", key: &rsaKey.PublicKey, method: jwt.SigningMethodRS256, want: true},
+		{name: "rsa RS512", key: &rsaKey.PublicKey, method: jwt.SigningMethodRS512},
+		{name: "rsa HS256", key: &rsaKey.PublicKey, method: jwt.SigningMethodHS256},
+		{name: "rsa ES256", key: &rsaKey.PublicKey, method: jwt.SigningMethodES256},
+		{name: "ec ES256", key: &ecKey.PublicKey, method: jwt.SigningMethodES256, want: true},
+		{name: "ec HS256", key: &ecKey.PublicKey, method: jwt.SigningMethodHS256},
+		{name: "ec RS256", key: &ecKey.PublicKey, method: jwt.SigningMethodRS256},
+		{name: "secret HS256", key: []byte("secret"), method: jwt.SigningMethodHS256},
+		{name: "nil", key: nil, method: jwt.SigningMethodRS256},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := keyMatchesMethod(tt.key, tt.method); got != tt.want {
+				t.Errorf("keyMatchesMethod = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// stubUserRepo returns the same user for every id.
+type stubUserRepo struct {
+	domain.UserRepository
+	user *domain.User
+}
+
+func (r stubUserRepo) GetById(ctx context.Context, userId string) (*domain.User, error) {
+	return r.user, nil
+}
+
+// stubRevocationStore gives the same answer for every token.
+type stubRevocationStore struct {
+	domain.RevocationStore
+	revoked bool
+	err     error
+}
+
+func (s stubRevocationStore) IsRevoked(ctx context.Context, jti, userId string, issuedAt time.Time) (bool, error) {
+	return s.revoked, s.err
+}
+
+func TestProtectedRevocation(t *testing.T) {
+	user := &domain.User{Email: "luke@example.com"}
+	user.ID = primitive.NewObjectID()
+
+	keys := &domain.KeySet{Active: &domain.SigningKey{Method: jwt.SigningMethodHS256, Key: []byte("secret")}}
+	token, err := domain.GenerateToken(keys, *user)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name  string
+		store domain.RevocationStore
+		want  int
+	}{
+		{name: "no store", store: nil, want: fiber.StatusOK},
+		{name: "valid", store: stubRevocationStore{}, want: fiber.StatusOK},
+		{name: "revoked", store: stubRevocationStore{revoked: true}, want: fiber.StatusUnauthorized},
+		{name: "store error", store: stubRevocationStore{err: errors.New("store down")}, want: fiber.StatusServiceUnavailable},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := fiber.New()
+			app.Get("/", Protected(stubUserRepo{user: user}, keys, tt.store), func(c *fiber.Ctx) error {
+				return c.SendStatus(fiber.StatusOK)
+			})
+
+			req := httptest.NewRequest(fiber.MethodGet, "/", nil)
+			req.Header.Set(fiber.HeaderAuthorization, "Bearer "+token)
+
+			resp, err := app.Test(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if resp.StatusCode != tt.want {
+				t.Errorf("status = %d, want %d", resp.StatusCode, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,73 @@
+package planet
+
+import (
+	"context"
+	"movies-review-api/delivery/http/middleware"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+	"movies-review-api/domain"
+	"movies-review-api/pkg/logger"
+)
+
+type PlanetHandler struct {
+	PlanetRepo domain.PlanetRepository
+	Logger     *zap.Logger
+}
+
+func New(planetRouter fiber.Router, r domain.PlanetRepository, userRepo domain.UserRepository, keys *domain.KeySet, revocationStore domain.RevocationStore) {
+	handler := &PlanetHandler{
+		PlanetRepo: r,
+	}
+
+	l, _ := logger.InitLogger()
+
+	handler.Logger = l
+
+	planetRouter.Get("/", middleware.Protected(userRepo, keys, revocationStore), handler.FetchPaginatedPlanets)
+	planetRouter.Get("/:id", middleware.Protected(userRepo, keys, revocationStore), handler.FetchSinglePlanet)
+}
+
+func (h *PlanetHandler) FetchPaginatedPlanets(c *fiber.Ctx) error {
+	page := c.Query("page", "1")
+
+	pageInt, err := strconv.Atoi(page)
+	if err != nil {
+		return domain.HandleError(c, err)
+	}
+
+	limit := c.Query("limit", "20")
+
+	limitInt, err := strconv.Atoi(limit)
+	if err != nil {
+		return domain.HandleError(c, err)
+	}
+
+	data, err := h.PlanetRepo.FetchPaginatedPlanets(context.TODO(), int64(pageInt), int64(limitInt))
+
+	if err != nil {
+		return domain.HandleError(c, err)
+	}
+
+	return c.JSON(fiber.Map{
+		"error": false,
+		"data":  data,
+	})
+}
+
+func (h *PlanetHandler) FetchSinglePlanet(c *fiber.Ctx) error {
+
+	id := c.Params("id")
+
+	data, err := h.PlanetRepo.GetById(context.TODO(), id)
+
+	if err != nil {
+		return domain.HandleError(c, err)
+	}
+
+	return c.JSON(fiber.Map{
+		"error": false,
+		"data":  data,
+	})
+}
@@ -0,0 +1,207 @@
+package review
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/go-playground/validator/v10"
+	"movies-review-api/delivery/http/middleware"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+	"movies-review-api/domain"
+	"movies-review-api/pkg/logger"
+)
+
+var (
+	validate = validator.New()
+)
+
+func init() {
+	// report invalid fields by their json name
+	validate.RegisterTagNameFunc(func(field reflect.StructField) string {
+		name := strings.SplitN(field.Tag.Get("json"), ",", 2)[0]
+		if name == "-" {
+			return ""
+		}
+		return name
+	})
+}
+
+type ReviewHandler struct {
+	ReviewUsecase domain.ReviewUsecase
+	Logger        *zap.Logger
+}
+
+func New(filmRouter fiber.Router, userRepo domain.UserRepository, keys *domain.KeySet, revocationStore domain.RevocationStore, reviewUsecase domain.ReviewUsecase) {
+	handler := &ReviewHandler{
+		ReviewUsecase: reviewUsecase,
+	}
+
+	l, _ := logger.InitLogger()
+
+	handler.Logger = l
+
+	filmRouter.Get("/:id/reviews", middleware.Protected(userRepo, keys, revocationStore), handler.FetchFilmReviews)
+	filmRouter.Post("/:id/reviews", middleware.Protected(userRepo, keys, revocationStore), handler.AddReview)
+	filmRouter.Get("/:id/reviews/:reviewId", middleware.Protected(userRepo, keys, revocationStore), handler.FetchReview)
+	filmRouter.Put("/:id/reviews/:reviewId", middleware.Protected(userRepo, keys, revocationStore), handler.UpdateReview)
+	filmRouter.Delete("/:id/reviews/:reviewId", middleware.Protected(userRepo, keys, revocationStore), handler.DeleteReview)
+	filmRouter.Put("/:id/reviews/:reviewId/helpful", middleware.Protected(userRepo, keys, revocationStore), handler.MarkHelpful)
+	filmRouter.Delete("/:id/reviews/:reviewId/helpful", middleware.Protected(userRepo, keys, revocationStore), handler.UnmarkHelpful)
+}
+
+func (h *ReviewHandler) FetchFilmReviews(c *fiber.Ctx) error {
+
+	page := c.Query("page", "1")
+
+	pageInt, err := strconv.Atoi(page)
+	if err != nil {
+		return domain.HandleError(c, err)
+	}
+
+	limit := c.Query("limit", "20")
+
+	limitInt, err := strconv.Atoi(limit)
+	if err != nil {
+		return domain.HandleError(c, err)
+	}
+
+	sort := strings.ToLower(c.Query("sort", domain.ReviewSortNewest))
+	if sort != domain.ReviewSortNewest && sort != domain.ReviewSortHelpful {
+		return domain.HandleFieldErrors(c, domain.FieldErrors{"sort": "must be one of [newest helpful]"})
+	}
+
+	data, err := h.ReviewUsecase.FetchFilmReviews(context.TODO(), c.Params("id"), sort, int64(pageInt), int64(limitInt))
+
+	if err != nil {
+		return domain.HandleError(c, err)
+	}
+
+	return c.JSON(fiber.Map{
+		"error": false,
+		"data":  data,
+	})
+}
+
+func (h *ReviewHandler) AddReview(c *fiber.Ctx) error {
+	var data domain.ReviewRequest
+
+	if err := json.Unmarshal(c.Body(), &data); err != nil {
+		return domain.HandleError(c, err)
+	}
+
+	if err := validate.Struct(data); err != nil {
+		return domain.HandleFieldErrors(c, domain.NewFieldErrors(err))
+	}
+
+	review, err := h.ReviewUsecase.AddReview(context.TODO(), c.Params("id"), c.Locals("user_id").(string), &data)
+
+	if err != nil {
+		return handleReviewError(c, err)
+	}
+
+	return c.JSON(fiber.Map{
+		"error": false,
+		"data":  review,
+	})
+}
+
+func (h *ReviewHandler) FetchReview(c *fiber.Ctx) error {
+
+	review, err := h.ReviewUsecase.FetchReview(context.TODO(), c.Params("id"), c.Params("reviewId"))
+
+	if err != nil {
+		return domain.HandleError(c, err)
+	}
+
+	return c.JSON(fiber.Map{
+		"error": false,
+		"data":  review,
+	})
+}
+
+func (h *ReviewHandler) UpdateReview(c *fiber.Ctx) error {
+	var data domain.ReviewRequest
+
+	if err := json.Unmarshal(c.Body(), &data); err != nil {
+		return domain.HandleError(c, err)
+	}
+
+	if err := validate.Struct(data); err != nil {
+		return domain.HandleFieldErrors(c, domain.NewFieldErrors(err))
+	}
+
+	review, err := h.ReviewUsecase.UpdateReview(context.TODO(), c.Params("id"), c.Params("reviewId"), c.Locals("user_id").(string), &data)
+
+	if err != nil {
+		return handleReviewError(c, err)
+	}
+
+	return c.JSON(fiber.Map{
+		"error": false,
+		"data":  review,
+	})
+}
+
+func (h *ReviewHandler) DeleteReview(c *fiber.Ctx) error {
+
+	err := h.ReviewUsecase.DeleteReview(context.TODO(), c.Params("id"), c.Params("reviewId"), c.Locals("user_id").(string), c.Locals("role").(string))
+
+	if err != nil {
+		return handleReviewError(c, err)
+	}
+
+	return c.JSON(fiber.Map{
+		"error": false,
+		"data":  nil,
+	})
+}
+
+func (h *ReviewHandler) MarkHelpful(c *fiber.Ctx) error {
+
+	vote, err := h.ReviewUsecase.MarkHelpful(context.TODO(), c.Params("id"), c.Params("reviewId"), c.Locals("user_id").(string))
+
+	if err != nil {
+		return handleReviewError(c, err)
+	}
+
+	return c.JSON(fiber.Map{
+		"error": false,
+		"data":  vote,
+	})
+}
+
+func (h *ReviewHandler) UnmarkHelpful(c *fiber.Ctx) error {
+
+	err := h.ReviewUsecase.UnmarkHelpful(context.TODO(), c.Params("id"), c.Params("reviewId"), c.Locals("user_id").(string))
+
+	if err != nil {
+		return handleReviewError(c, err)
+	}
+
+	return c.JSON(fiber.Map{
+		"error": false,
+		"data":  nil,
+	})
+}
+
+func handleReviewError(c *fiber.Ctx, err error) error {
+	status := fiber.StatusBadRequest
+
+	switch {
+	case errors.Is(err, domain.ErrReviewForbidden), errors.Is(err, domain.ErrOwnReviewHelpful):
+		status = fiber.StatusForbidden
+	case errors.Is(err, domain.ErrReviewExists):
+		status = fiber.StatusConflict
+	}
+
+	return c.Status(status).JSON(
+		fiber.Map{
+			"error": true,
+			"msg":   err.Error(),
+		})
+}
@@ -2,31 +2,63 @@ package http
 
 import (
 	"github.com/gofiber/fiber/v2"
+	"movies-review-api/delivery/http/admin"
+	"movies-review-api/delivery/http/character"
 	"movies-review-api/delivery/http/comment"
 	"movies-review-api/delivery/http/film"
+	"movies-review-api/delivery/http/planet"
+	"movies-review-api/delivery/http/review"
+	"movies-review-api/delivery/http/species"
+	"movies-review-api/delivery/http/starship"
 	"movies-review-api/delivery/http/user"
+	"movies-review-api/delivery/http/vehicle"
 
 	commentU "movies-review-api/application/comment"
-	filmU "movies-review-api/application/film"
+	moderationU "movies-review-api/application/moderation"
+	ratingU "movies-review-api/application/rating"
+	reviewU "movies-review-api/application/review"
 	userU "movies-review-api/application/user"
 )
 
 func setupRouter(app *fiber.App, config Config) {
 	app.Post("/api/v1/ping", ping)
 
+	// other services verify our tokens with the published keys
+	app.Get("/.well-known/jwks.json", jwks(config.KeySet))
+
 	// route group
 	v1 := app.Group("/api/v1/")
 	authRouter := v1.Group("/auth")
 	userRouter := v1.Group("/user")
 	filmRouter := v1.Group("/films")
 	commentRouter := v1.Group("/comments")
+	adminRouter := v1.Group("/admin")
+	characterRouter := v1.Group("/characters")
+	planetRouter := v1.Group("/planets")
+	starshipRouter := v1.Group("/starships")
+	vehicleRouter := v1.Group("/vehicles")
+	speciesRouter := v1.Group("/species")
+
+	userUseCase := userU.New(config.UserRepo, config.RefreshTokenRepo, config.RevocationStore, config.KeySet,
+		config.EmailVerificationRepo, config.Mailer)
+	user.New(userRouter, userUseCase, config.UserRepo, config.KeySet, config.RevocationStore, authRouter)
+
+	ratingUsecase := ratingU.New(config.RatingRepo, config.FilmRepo)
+	film.New(filmRouter, config.FilmRepo, config.UserRepo, config.KeySet, config.RevocationStore, config.CharacterRepo, ratingUsecase)
+
+	reviewUsecase := reviewU.New(config.ReviewRepo, config.ReviewVoteRepo, config.FilmRepo)
+	review.New(filmRouter, config.UserRepo, config.KeySet, config.RevocationStore, reviewUsecase)
 
-	userUseCase := userU.New(config.UserRepo)
-	user.New(userRouter, userUseCase, config.UserRepo, authRouter)
+	commentUsecase := commentU.New(config.CommentRepo, config.CommentRevisionRepo, config.ReactionRepo, config.FilmRepo,
+		config.CommentFilters...)
+	moderationUsecase := moderationU.New(config.CommentRepo, config.CommentReportRepo, config.ModerationRepo, config.UserRepo)
+	comment.New(commentRouter, config.CommentRepo, config.UserRepo, config.KeySet, config.RevocationStore, commentUsecase, moderationUsecase)
 
-	filmUsecase := filmU.New(config.FilmRepo)
-	film.New(filmRouter, config.FilmRepo, config.UserRepo, filmUsecase)
+	admin.New(adminRouter, config.SyncRunRepo, config.FilmRepo, config.UserRepo, config.KeySet, config.RevocationStore, userUseCase)
 
-	commentUsecase := commentU.New(config.CommentRepo)
-	comment.New(commentRouter, config.CommentRepo, config.UserRepo, commentUsecase)
+	character.New(characterRouter, config.CharacterRepo, config.UserRepo, config.KeySet, config.RevocationStore)
+	planet.New(planetRouter, config.PlanetRepo, config.UserRepo, config.KeySet, config.RevocationStore)
+	starship.New(starshipRouter, config.StarshipRepo, config.UserRepo, config.KeySet, config.RevocationStore)
+	vehicle.New(vehicleRouter, config.VehicleRepo, config.UserRepo, config.KeySet, config.RevocationStore)
+	species.New(speciesRouter, config.SpeciesRepo, config.UserRepo, config.KeySet, config.RevocationStore)
 }
@@ -3,17 +3,40 @@ package http
 import (
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/middleware/cors"
+	commentU "movies-review-api/application/comment"
 	"movies-review-api/domain"
+	"os"
+	"strings"
 )
 
 type Config struct {
-	UserRepo    domain.UserRepository
-	FilmRepo    domain.FilmRepository
-	CommentRepo domain.CommentRepository
+	UserRepo      domain.UserRepository
+	FilmRepo      domain.FilmRepository
+	CommentRepo   domain.CommentRepository
+	SyncRunRepo   domain.SyncRunRepository
+	CharacterRepo domain.CharacterRepository
+	PlanetRepo    domain.PlanetRepository
+	StarshipRepo  domain.StarshipRepository
+	VehicleRepo   domain.VehicleRepository
+	SpeciesRepo   domain.SpeciesRepository
+
+	CommentRevisionRepo   domain.CommentRevisionRepository
+	ReactionRepo          domain.ReactionRepository
+	RatingRepo            domain.RatingRepository
+	ReviewRepo            domain.ReviewRepository
+	ReviewVoteRepo        domain.ReviewVoteRepository
+	CommentReportRepo     domain.CommentReportRepository
+	ModerationRepo        domain.ModerationActionRepository
+	RefreshTokenRepo      domain.RefreshTokenRepository
+	RevocationStore       domain.RevocationStore
+	KeySet                *domain.KeySet
+	EmailVerificationRepo domain.EmailVerificationRepository
+	Mailer                domain.Mailer
+	CommentFilters        []commentU.ModerationFilter
 }
 
 func RunHttpServer(config Config) *fiber.App {
-	app := fiber.New()
+	app := fiber.New(proxyConfig())
 	app.Use(cors.New())
 
 	// setup routes
@@ -21,3 +44,28 @@ func RunHttpServer(config Config) *fiber.App {
 
 	return app
 }
+
+// proxyConfig reads the client ip from PROXY_HEADER (e.g. X-Forwarded-For)
+// when the request comes from one of the comma separated TRUSTED_PROXIES.
+// PROXY_HEADER is ignored when no proxy is trusted, since any client could
+// set it. Fiber takes the left-most address of the header, which is the one
+// the client sent, so the trusted proxy must overwrite the header rather
+// than append to it.
+func proxyConfig() fiber.Config {
+	config := fiber.Config{
+		EnableIPValidation: true,
+	}
+
+	for _, proxy := range strings.Split(os.Getenv("TRUSTED_PROXIES"), ",") {
+		if proxy = strings.TrimSpace(proxy); proxy != "" {
+			config.TrustedProxies = append(config.TrustedProxies, proxy)
+		}
+	}
+
+	if len(config.TrustedProxies) > 0 {
+		config.ProxyHeader = os.Getenv("PROXY_HEADER")
+		config.EnableTrustedProxyCheck = true
+	}
+
+	return config
+}
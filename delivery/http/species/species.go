@@ -0,0 +1,73 @@
+package species
+
+import (
+	"context"
+	"movies-review-api/delivery/http/middleware"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+	"movies-review-api/domain"
+	"movies-review-api/pkg/logger"
+)
+
+type SpeciesHandler struct {
+	SpeciesRepo domain.SpeciesRepository
+	Logger      *zap.Logger
+}
+
+func New(speciesRouter fiber.Router, r domain.SpeciesRepository, userRepo domain.UserRepository, keys *domain.KeySet, revocationStore domain.RevocationStore) {
+	handler := &SpeciesHandler{
+		SpeciesRepo: r,
+	}
+
+	l, _ := logger.InitLogger()
+
+	handler.Logger = l
+
+	speciesRouter.Get("/", middleware.Protected(userRepo, keys, revocationStore), handler.FetchPaginatedSpecies)
+	speciesRouter.Get("/:id", middleware.Protected(userRepo, keys, revocationStore), handler.FetchSingleSpecies)
+}
+
+func (h *SpeciesHandler) FetchPaginatedSpecies(c *fiber.Ctx) error {
+	page := c.Query("page", "1")
+
+	pageInt, err := strconv.Atoi(page)
+	if err != nil {
+		return domain.HandleError(c, err)
+	}
+
+	limit := c.Query("limit", "20")
+
+	limitInt, err := strconv.Atoi(limit)
+	if err != nil {
+		return domain.HandleError(c, err)
+	}
+
+	data, err := h.SpeciesRepo.FetchPaginatedSpecies(context.TODO(), int64(pageInt), int64(limitInt))
+
+	if err != nil {
+		return domain.HandleError(c, err)
+	}
+
+	return c.JSON(fiber.Map{
+		"error": false,
+		"data":  data,
+	})
+}
+
+func (h *SpeciesHandler) FetchSingleSpecies(c *fiber.Ctx) error {
+
+	id := c.Params("id")
+
+	data, err := h.SpeciesRepo.GetById(context.TODO(), id)
+
+	if err != nil {
+		return domain.HandleError(c, err)
+	}
+
+	return c.JSON(fiber.Map{
+		"error": false,
+		"data":  data,
+	})
+}
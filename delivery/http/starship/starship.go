@@ -0,0 +1,73 @@
+package starship
+
+import (
+	"context"
+	"movies-review-api/delivery/http/middleware"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+	"movies-review-api/domain"
+	"movies-review-api/pkg/logger"
+)
+
+type StarshipHandler struct {
+	StarshipRepo domain.StarshipRepository
+	Logger       *zap.Logger
+}
+
+func New(starshipRouter fiber.Router, r domain.StarshipRepository, userRepo domain.UserRepository, keys *domain.KeySet, revocationStore domain.RevocationStore) {
+	handler := &StarshipHandler{
+		StarshipRepo: r,
+	}
+
+	l, _ := logger.InitLogger()
+
+	handler.Logger = l
+
+	starshipRouter.Get("/", middleware.Protected(userRepo, keys, revocationStore), handler.FetchPaginatedStarships)
+	starshipRouter.Get("/:id", middleware.Protected(userRepo, keys, revocationStore), handler.FetchSingleStarship)
+}
+
+func (h *StarshipHandler) FetchPaginatedStarships(c *fiber.Ctx) error {
+	page := c.Query("page", "1")
+
+	pageInt, err := strconv.Atoi(page)
+	if err != nil {
+		return domain.HandleError(c, err)
+	}
+
+	limit := c.Query("limit", "20")
+
+	limitInt, err := strconv.Atoi(limit)
+	if err != nil {
+		return domain.HandleError(c, err)
+	}
+
+	data, err := h.StarshipRepo.FetchPaginatedStarships(context.TODO(), int64(pageInt), int64(limitInt))
+
+	if err != nil {
+		return domain.HandleError(c, err)
+	}
+
+	return c.JSON(fiber.Map{
+		"error": false,
+		"data":  data,
+	})
+}
+
+func (h *StarshipHandler) FetchSingleStarship(c *fiber.Ctx) error {
+
+	id := c.Params("id")
+
+	data, err := h.StarshipRepo.GetById(context.TODO(), id)
+
+	if err != nil {
+		return domain.HandleError(c, err)
+	}
+
+	return c.JSON(fiber.Map{
+		"error": false,
+		"data":  data,
+	})
+}
@@ -3,6 +3,7 @@ package user
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"github.com/go-playground/validator/v10"
 	"movies-review-api/delivery/http/middleware"
 
@@ -24,7 +25,7 @@ type UserHandler struct {
 	Logger      *zap.Logger
 }
 
-func New(userRouter fiber.Router, u domain.UserUsecase, r domain.UserRepository, auth fiber.Router) {
+func New(userRouter fiber.Router, u domain.UserUsecase, r domain.UserRepository, keys *domain.KeySet, revocationStore domain.RevocationStore, auth fiber.Router) {
 	handler := &UserHandler{
 		UserUsecase: u,
 		UserRepo:    r,
@@ -35,8 +36,12 @@ func New(userRouter fiber.Router, u domain.UserUsecase, r domain.UserRepository,
 	handler.Logger = l
 
 	auth.Post("/login", handler.Login)
+	auth.Post("/refresh", handler.Refresh)
+	auth.Post("/logout", middleware.Protected(r, keys, revocationStore), handler.Logout)
+	auth.Post("/verify-email", handler.VerifyEmail)
+	auth.Post("/resend-verification", middleware.Protected(r, keys, revocationStore), handler.ResendVerification)
 	userRouter.Post("/signup", handler.SignUp)
-	userRouter.Get("/profile", middleware.Protected(r), handler.FetchUserProfile)
+	userRouter.Get("/profile", middleware.Protected(r, keys, revocationStore), handler.FetchUserProfile)
 }
 
 func (h *UserHandler) SignUp(c *fiber.Ctx) error {
@@ -50,8 +55,16 @@ func (h *UserHandler) SignUp(c *fiber.Ctx) error {
 		return domain.HandleValidationError(c, err)
 	}
 
+	data.Email = strings.ToLower(data.Email)
+
 	_, err := h.UserUsecase.Signup(context.TODO(), &data)
 
+	// the account is created, the user can ask for a new verification email
+	if errors.Is(err, domain.ErrVerificationEmailNotSent) {
+		h.Logger.Error(err.Error(), zap.Error(err))
+		err = nil
+	}
+
 	if err != nil {
 		h.Logger.Error(err.Error(), zap.Error(err))
 		return domain.HandleError(c, err)
@@ -87,7 +100,7 @@ func (h *UserHandler) Login(c *fiber.Ctx) error {
 		return domain.HandleError(c, err)
 	}
 
-	token, err := domain.GenerateToken(*existingUser)
+	tokens, err := h.UserUsecase.IssueTokens(context.TODO(), existingUser)
 
 	if err != nil {
 		return c.Status(400).JSON(
@@ -102,12 +115,113 @@ func (h *UserHandler) Login(c *fiber.Ctx) error {
 	return c.JSON(fiber.Map{
 		"error": false,
 		"data": fiber.Map{
-			"user":  existingUser,
-			"token": token,
+			"user":          existingUser,
+			"token":         tokens.AccessToken,
+			"refresh_token": tokens.RefreshToken,
+			"expires_in":    tokens.ExpiresIn,
 		},
 	})
 }
 
+func (h *UserHandler) Refresh(c *fiber.Ctx) error {
+	var data domain.RefreshRequest
+
+	if err := json.Unmarshal(c.Body(), &data); err != nil {
+		return domain.HandleError(c, err)
+	}
+
+	if err := validate.Struct(data); err != nil {
+		return domain.HandleValidationError(c, err)
+	}
+
+	tokens, err := h.UserUsecase.RefreshTokens(context.TODO(), data.RefreshToken)
+
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(
+			fiber.Map{
+				"error": true,
+				"msg":   err.Error(),
+			})
+	}
+
+	return c.JSON(fiber.Map{
+		"error": false,
+		"data":  tokens,
+	})
+}
+
+func (h *UserHandler) Logout(c *fiber.Ctx) error {
+	var data domain.LogoutRequest
+
+	// the body is optional, the access token alone is enough to log out
+	if len(c.Body()) > 0 {
+		if err := json.Unmarshal(c.Body(), &data); err != nil {
+			return domain.HandleError(c, err)
+		}
+	}
+
+	principal := c.Locals("principal").(*domain.Principal)
+
+	err := h.UserUsecase.Logout(c.UserContext(), principal.UserId, principal.TokenId, principal.ExpiresAt, data.RefreshToken)
+
+	if err != nil {
+		return domain.HandleError(c, err)
+	}
+
+	return c.JSON(fiber.Map{
+		"error": false,
+		"data":  nil,
+	})
+}
+
+func (h *UserHandler) VerifyEmail(c *fiber.Ctx) error {
+	var data domain.VerifyEmailRequest
+
+	if err := json.Unmarshal(c.Body(), &data); err != nil {
+		return domain.HandleError(c, err)
+	}
+
+	if err := validate.Struct(data); err != nil {
+		return domain.HandleValidationError(c, err)
+	}
+
+	user, err := h.UserUsecase.VerifyEmail(context.TODO(), data.Token)
+
+	if err != nil {
+		return domain.HandleError(c, err)
+	}
+
+	user.Password = ""
+
+	return c.JSON(fiber.Map{
+		"error": false,
+		"data":  user,
+	})
+}
+
+func (h *UserHandler) ResendVerification(c *fiber.Ctx) error {
+
+	err := h.UserUsecase.ResendVerification(context.TODO(), c.Locals("user_id").(string))
+
+	if errors.Is(err, domain.ErrVerificationEmailNotSent) {
+		h.Logger.Error(err.Error(), zap.Error(err))
+		return c.Status(fiber.StatusServiceUnavailable).JSON(
+			fiber.Map{
+				"error": true,
+				"msg":   domain.ErrVerificationEmailNotSent.Error(),
+			})
+	}
+
+	if err != nil {
+		return domain.HandleError(c, err)
+	}
+
+	return c.JSON(fiber.Map{
+		"error": false,
+		"data":  nil,
+	})
+}
+
 func (h *UserHandler) FetchUserProfile(c *fiber.Ctx) error {
 
 	id := c.Locals("user_id").(string)
@@ -0,0 +1,73 @@
+package vehicle
+
+import (
+	"context"
+	"movies-review-api/delivery/http/middleware"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+	"movies-review-api/domain"
+	"movies-review-api/pkg/logger"
+)
+
+type VehicleHandler struct {
+	VehicleRepo domain.VehicleRepository
+	Logger      *zap.Logger
+}
+
+func New(vehicleRouter fiber.Router, r domain.VehicleRepository, userRepo domain.UserRepository, keys *domain.KeySet, revocationStore domain.RevocationStore) {
+	handler := &VehicleHandler{
+		VehicleRepo: r,
+	}
+
+	l, _ := logger.InitLogger()
+
+	handler.Logger = l
+
+	vehicleRouter.Get("/", middleware.Protected(userRepo, keys, revocationStore), handler.FetchPaginatedVehicles)
+	vehicleRouter.Get("/:id", middleware.Protected(userRepo, keys, revocationStore), handler.FetchSingleVehicle)
+}
+
+func (h *VehicleHandler) FetchPaginatedVehicles(c *fiber.Ctx) error {
+	page := c.Query("page", "1")
+
+	pageInt, err := strconv.Atoi(page)
+	if err != nil {
+		return domain.HandleError(c, err)
+	}
+
+	limit := c.Query("limit", "20")
+
+	limitInt, err := strconv.Atoi(limit)
+	if err != nil {
+		return domain.HandleError(c, err)
+	}
+
+	data, err := h.VehicleRepo.FetchPaginatedVehicles(context.TODO(), int64(pageInt), int64(limitInt))
+
+	if err != nil {
+		return domain.HandleError(c, err)
+	}
+
+	return c.JSON(fiber.Map{
+		"error": false,
+		"data":  data,
+	})
+}
+
+func (h *VehicleHandler) FetchSingleVehicle(c *fiber.Ctx) error {
+
+	id := c.Params("id")
+
+	data, err := h.VehicleRepo.GetById(context.TODO(), id)
+
+	if err != nil {
+		return domain.HandleError(c, err)
+	}
+
+	return c.JSON(fiber.Map{
+		"error": false,
+		"data":  data,
+	})
+}
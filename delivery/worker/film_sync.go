@@ -0,0 +1,132 @@
+package worker
+
+import (
+	"context"
+	"math/rand"
+	"movies-review-api/domain"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+const (
+	DefaultFilmSyncInterval = time.Hour
+	DefaultFilmSyncJitter   = 5 * time.Minute
+)
+
+type FilmSyncConfig struct {
+	// Interval between two sync runs.
+	// Optional. Default: FILM_SYNC_INTERVAL or 1h
+	Interval time.Duration
+
+	// Jitter is the upper bound of the random delay added to every interval.
+	// Optional. Default: FILM_SYNC_JITTER or 5m
+	Jitter time.Duration
+}
+
+// FilmSyncWorker periodically syncs the films collection from every registered source.
+type FilmSyncWorker struct {
+	FilmUsecase domain.FilmUsecase
+	Logger      *zap.Logger
+	Config      FilmSyncConfig
+
+	running atomic.Bool
+	wg      sync.WaitGroup
+}
+
+func NewFilmSyncWorker(logger *zap.Logger, filmUsecase domain.FilmUsecase, config ...FilmSyncConfig) *FilmSyncWorker {
+	var cfg FilmSyncConfig
+	if len(config) > 0 {
+		cfg = config[0]
+	}
+	if cfg.Interval <= 0 {
+		cfg.Interval = durationFromEnv(logger, "FILM_SYNC_INTERVAL", DefaultFilmSyncInterval)
+	}
+	if cfg.Jitter <= 0 {
+		cfg.Jitter = durationFromEnv(logger, "FILM_SYNC_JITTER", DefaultFilmSyncJitter)
+	}
+
+	return &FilmSyncWorker{
+		FilmUsecase: filmUsecase,
+		Logger:      logger,
+		Config:      cfg,
+	}
+}
+
+// Start runs a sync right away and then once every interval until ctx is
+// cancelled. It blocks until the in-flight run, if any, has returned.
+func (w *FilmSyncWorker) Start(ctx context.Context) {
+	rnd := rand.New(rand.NewSource(time.Now().UnixNano()))
+
+	w.trigger(ctx)
+
+	for {
+		delay := w.Config.Interval
+		if w.Config.Jitter > 0 {
+			delay += time.Duration(rnd.Int63n(int64(w.Config.Jitter)))
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			w.wg.Wait()
+			return
+		case <-timer.C:
+			w.trigger(ctx)
+		}
+	}
+}
+
+func (w *FilmSyncWorker) trigger(ctx context.Context) {
+	if !w.running.CompareAndSwap(false, true) {
+		w.Logger.Warn("film sync skipped, previous run still in progress")
+		return
+	}
+
+	w.wg.Add(1)
+	go func() {
+		defer w.wg.Done()
+		defer w.running.Store(false)
+
+		w.run(ctx)
+	}()
+}
+
+func (w *FilmSyncWorker) run(ctx context.Context) {
+	runs := w.FilmUsecase.SyncFilmsFromAllSources(ctx)
+
+	for _, run := range runs {
+		fields := []zap.Field{
+			zap.String("source", run.Source),
+			zap.Duration("duration", run.FinishedAt.Sub(run.StartedAt)),
+			zap.Int64("pages_fetched", run.PagesFetched),
+			zap.Int64("films_inserted", run.FilmsInserted),
+			zap.Int64("films_updated", run.FilmsUpdated),
+			zap.Int64("films_retired", run.FilmsRetired),
+		}
+		if run.Error != "" {
+			w.Logger.Error("film sync failed", append(fields, zap.String("error", run.Error))...)
+			continue
+		}
+		w.Logger.Info("film sync completed", fields...)
+	}
+}
+
+func durationFromEnv(logger *zap.Logger, key string, fallback time.Duration) time.Duration {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback
+	}
+
+	d, err := time.ParseDuration(value)
+	if err != nil || d <= 0 {
+		logger.Warn("invalid duration, using default", zap.String("key", key), zap.String("value", value))
+		return fallback
+	}
+
+	return d
+}
@@ -0,0 +1,436 @@
+package domain
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/Kamva/mgm/v2"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.uber.org/zap"
+)
+
+// ResourceKind is a kind of resource films relate to. It doubles as the
+// name of the collection the resources are saved in.
+type ResourceKind string
+
+const (
+	ResourceCharacters ResourceKind = "characters"
+	ResourcePlanets    ResourceKind = "planets"
+	ResourceStarships  ResourceKind = "starships"
+	ResourceVehicles   ResourceKind = "vehicles"
+	ResourceSpecies    ResourceKind = "species"
+)
+
+// ResourceKinds are synced in this order.
+var ResourceKinds = []ResourceKind{
+	ResourcePlanets,
+	ResourceSpecies,
+	ResourceVehicles,
+	ResourceStarships,
+	ResourceCharacters,
+}
+
+// NewResource returns an empty resource of the given kind.
+func NewResource(kind ResourceKind) Resource {
+	switch kind {
+	case ResourceCharacters:
+		return &Character{}
+	case ResourcePlanets:
+		return &Planet{}
+	case ResourceStarships:
+		return &Starship{}
+	case ResourceVehicles:
+		return &Vehicle{}
+	case ResourceSpecies:
+		return &Species{}
+	}
+	return nil
+}
+
+// SourceResource holds the fields every resource synced from a source shares.
+type SourceResource struct {
+	Source          string               `json:"source" bson:"source"`
+	ExternalId      string               `json:"external_id" bson:"external_id"`
+	Films           []string             `json:"films" bson:"films"`
+	FilmIds         []primitive.ObjectID `json:"film_ids,omitempty" bson:"film_ids,omitempty"`
+	SourceCreatedAt time.Time            `json:"source_created_at" bson:"source_created_at"`
+	SourceEditedAt  time.Time            `json:"source_edited_at" bson:"source_edited_at"`
+	RetiredAt       *time.Time           `json:"retired_at,omitempty" bson:"retired_at"`
+}
+
+func (m *SourceResource) GetSourceResource() *SourceResource {
+	return m
+}
+
+// Resource is a model synced from a source and keyed on its ExternalId.
+type Resource interface {
+	mgm.Model
+	GetSourceResource() *SourceResource
+}
+
+type ResourcePage struct {
+	Url     string     `json:"url" bson:"url"`
+	Next    string     `json:"next" bson:"next"`
+	Count   int64      `json:"count" bson:"count"`
+	Results []Resource `json:"results" bson:"results"`
+}
+
+// CatalogSource is a FilmSource that also serves the resources films relate to.
+type CatalogSource interface {
+	FilmSource
+	// FetchResourcePage returns a single page of resources of the given kind.
+	// An empty pageUrl means the first page.
+	FetchResourcePage(ctx context.Context, kind ResourceKind, pageUrl string) (*ResourcePage, error)
+}
+
+// ResourceSyncStats are the statistics of syncing one kind of resource.
+type ResourceSyncStats struct {
+	Kind         ResourceKind `json:"kind" bson:"kind"`
+	PagesFetched int64        `json:"pages_fetched" bson:"pages_fetched"`
+	Inserted     int64        `json:"inserted" bson:"inserted"`
+	Updated      int64        `json:"updated" bson:"updated"`
+	Unchanged    int64        `json:"unchanged" bson:"unchanged"`
+	Retired      int64        `json:"retired" bson:"retired"`
+}
+
+// UpdateResourcesFromSource walks every page of every resource kind of the
+// given source and upserts the resources on their ExternalId. Pages whose
+// hash did not change are skipped, and resources that are gone from the
+// source are marked as retired once every page of their kind was read.
+func UpdateResourcesFromSource(ctx context.Context, logger *zap.Logger, source CatalogSource, run *SyncRun) error {
+	for _, kind := range ResourceKinds {
+		stats := ResourceSyncStats{Kind: kind}
+		err := updateResourceKindFromSource(ctx, logger, source, kind, &stats, run)
+		run.Resources = append(run.Resources, stats)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func updateResourceKindFromSource(ctx context.Context, logger *zap.Logger, source CatalogSource, kind ResourceKind, stats *ResourceSyncStats, run *SyncRun) error {
+	var (
+		pageUrl     string
+		externalIds []string
+	)
+
+	for {
+		data, err := source.FetchResourcePage(ctx, kind, pageUrl)
+		if err != nil {
+			return err
+		}
+		stats.PagesFetched++
+
+		for _, resource := range data.Results {
+			externalIds = append(externalIds, resource.GetSourceResource().ExternalId)
+		}
+
+		savedData, err := findStarwarsDataHash(ctx, source.Name(), data.Url, false)
+		if err != nil {
+			return err
+		}
+
+		newHash, err := hashStarwarsApiData(data.Results)
+		if err != nil {
+			return err
+		}
+
+		if savedData.Hash == newHash {
+			stats.Unchanged += int64(len(data.Results))
+		} else {
+			failed := false
+			for _, resource := range data.Results {
+				if err = upsertSourceResource(ctx, resource, stats); err != nil {
+					logger.Error("Error:", zap.Error(err))
+					run.Errors = append(run.Errors, fmt.Sprintf("%s %s: %v", kind, resource.GetSourceResource().ExternalId, err))
+					failed = true
+				}
+			}
+
+			// the page is read again next run until every resource of it is saved
+			if !failed {
+				savedData.Count = int64(len(data.Results))
+				if err = saveStarwarsDataHash(ctx, savedData, newHash); err != nil {
+					return err
+				}
+			}
+		}
+
+		if data.Next == "" {
+			return retireSourceResources(ctx, source.Name(), kind, externalIds, stats)
+		}
+		pageUrl = data.Next
+	}
+}
+
+// upsertSourceResource creates the source resource, or updates the saved
+// resource with the same ExternalId when any of its source fields changed.
+func upsertSourceResource(ctx context.Context, resource Resource, stats *ResourceSyncStats) error {
+	raw, err := bson.Marshal(resource)
+	if err != nil {
+		return err
+	}
+
+	var fields bson.M
+	if err = bson.Unmarshal(raw, &fields); err != nil {
+		return err
+	}
+	delete(fields, "_id")
+	delete(fields, "created_at")
+	delete(fields, "updated_at")
+
+	now := time.Now().UTC()
+	coll := mgm.Coll(resource)
+	key := resource.GetSourceResource()
+	filter := bson.M{"source": key.Source, "external_id": key.ExternalId}
+
+	var saved bson.M
+	err = coll.FindOne(ctx, filter).Decode(&saved)
+	if err == mongo.ErrNoDocuments {
+		fields["updated_at"] = now
+		_, err = coll.UpdateOne(ctx, filter,
+			bson.M{"$set": fields, "$setOnInsert": bson.M{"created_at": now}},
+			options.Update().SetUpsert(true))
+		if err != nil {
+			return err
+		}
+		stats.Inserted++
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	// both sides went through bson, so equal values have the same types
+	update := bson.M{}
+	for field, value := range fields {
+		if !reflect.DeepEqual(saved[field], value) {
+			update[field] = value
+		}
+	}
+
+	if len(update) == 0 {
+		stats.Unchanged++
+		return nil
+	}
+
+	update["updated_at"] = now
+	if _, err = coll.UpdateByID(ctx, saved["_id"], bson.M{"$set": update}); err != nil {
+		return err
+	}
+	stats.Updated++
+	return nil
+}
+
+// retireSourceResources marks the resources of a kind and source that are
+// not in externalIds as retired.
+func retireSourceResources(ctx context.Context, source string, kind ResourceKind, externalIds []string, stats *ResourceSyncStats) error {
+	if externalIds == nil {
+		externalIds = []string{}
+	}
+
+	now := time.Now().UTC()
+	result, err := mgm.CollectionByName(string(kind)).UpdateMany(ctx, bson.M{
+		"source":      source,
+		"external_id": bson.M{"$nin": externalIds},
+		"retired_at":  nil,
+	}, bson.M{"$set": bson.M{"retired_at": now, "updated_at": now}})
+	if err != nil {
+		return err
+	}
+
+	stats.Retired = result.ModifiedCount
+	return nil
+}
+
+// sourceRelation maps the source urls held in UrlField to the ids of the
+// Target documents they point to, saved in IdField.
+type sourceRelation struct {
+	UrlField string
+	IdField  string
+	Target   string
+	Single   bool
+}
+
+var sourceRelations = map[string][]sourceRelation{
+	"films": {
+		{UrlField: "characters", IdField: "character_ids", Target: string(ResourceCharacters)},
+		{UrlField: "planets", IdField: "planet_ids", Target: string(ResourcePlanets)},
+		{UrlField: "starships", IdField: "starship_ids", Target: string(ResourceStarships)},
+		{UrlField: "vehicles", IdField: "vehicle_ids", Target: string(ResourceVehicles)},
+		{UrlField: "species", IdField: "species_ids", Target: string(ResourceSpecies)},
+	},
+	string(ResourceCharacters): {
+		{UrlField: "homeworld", IdField: "homeworld_id", Target: string(ResourcePlanets), Single: true},
+		{UrlField: "films", IdField: "film_ids", Target: "films"},
+		{UrlField: "species", IdField: "species_ids", Target: string(ResourceSpecies)},
+		{UrlField: "vehicles", IdField: "vehicle_ids", Target: string(ResourceVehicles)},
+		{UrlField: "starships", IdField: "starship_ids", Target: string(ResourceStarships)},
+	},
+	string(ResourcePlanets): {
+		{UrlField: "residents", IdField: "resident_ids", Target: string(ResourceCharacters)},
+		{UrlField: "films", IdField: "film_ids", Target: "films"},
+	},
+	string(ResourceStarships): {
+		{UrlField: "pilots", IdField: "pilot_ids", Target: string(ResourceCharacters)},
+		{UrlField: "films", IdField: "film_ids", Target: "films"},
+	},
+	string(ResourceVehicles): {
+		{UrlField: "pilots", IdField: "pilot_ids", Target: string(ResourceCharacters)},
+		{UrlField: "films", IdField: "film_ids", Target: "films"},
+	},
+	string(ResourceSpecies): {
+		{UrlField: "homeworld", IdField: "homeworld_id", Target: string(ResourcePlanets), Single: true},
+		{UrlField: "people", IdField: "people_ids", Target: string(ResourceCharacters)},
+		{UrlField: "films", IdField: "film_ids", Target: "films"},
+	},
+}
+
+// ResolveSourceRelations resolves the source urls films and resources hold
+// for each other into the ObjectIDs of the saved documents, so relations can
+// be read without calling the source.
+func ResolveSourceRelations(ctx context.Context, source string, run *SyncRun) error {
+	ids := map[string]map[string]primitive.ObjectID{}
+	for coll := range sourceRelations {
+		collIds, err := loadSourceIds(ctx, coll, source)
+		if err != nil {
+			return err
+		}
+		ids[coll] = collIds
+	}
+
+	for coll, relations := range sourceRelations {
+		cursor, err := mgm.CollectionByName(coll).Find(ctx, bson.M{"source": source})
+		if err != nil {
+			return err
+		}
+
+		var docs []bson.M
+		if err = cursor.All(ctx, &docs); err != nil {
+			return err
+		}
+
+		for _, doc := range docs {
+			set, unset := bson.M{}, bson.M{}
+
+			for _, relation := range relations {
+				resolved := resolveUrls(doc[relation.UrlField], ids[relation.Target])
+				saved := objectIds(doc[relation.IdField])
+
+				if relation.Single {
+					switch {
+					case len(resolved) == 0 && len(saved) > 0:
+						unset[relation.IdField] = ""
+					case len(resolved) > 0 && (len(saved) == 0 || saved[0] != resolved[0]):
+						set[relation.IdField] = resolved[0]
+					}
+					continue
+				}
+
+				if !sameObjectIds(saved, resolved) {
+					set[relation.IdField] = resolved
+				}
+			}
+
+			if len(set) == 0 && len(unset) == 0 {
+				continue
+			}
+
+			update := bson.M{}
+			if len(set) > 0 {
+				update["$set"] = set
+			}
+			if len(unset) > 0 {
+				update["$unset"] = unset
+			}
+
+			if _, err = mgm.CollectionByName(coll).UpdateByID(ctx, doc["_id"], update); err != nil {
+				return err
+			}
+			run.RelationsResolved++
+		}
+	}
+
+	return nil
+}
+
+func loadSourceIds(ctx context.Context, coll, source string) (map[string]primitive.ObjectID, error) {
+	cursor, err := mgm.CollectionByName(coll).Find(ctx, bson.M{"source": source},
+		options.Find().SetProjection(bson.M{"external_id": 1}))
+	if err != nil {
+		return nil, err
+	}
+
+	var docs []struct {
+		ID         primitive.ObjectID `bson:"_id"`
+		ExternalId string             `bson:"external_id"`
+	}
+	if err = cursor.All(ctx, &docs); err != nil {
+		return nil, err
+	}
+
+	ids := make(map[string]primitive.ObjectID, len(docs))
+	for _, doc := range docs {
+		ids[doc.ExternalId] = doc.ID
+	}
+	return ids, nil
+}
+
+// resolveUrls maps a single url or a list of urls to the ids they point to.
+// Urls that are not saved yet are left out.
+func resolveUrls(value interface{}, ids map[string]primitive.ObjectID) []primitive.ObjectID {
+	var urls []string
+	switch v := value.(type) {
+	case string:
+		urls = []string{v}
+	case primitive.A:
+		for _, url := range v {
+			if s, ok := url.(string); ok {
+				urls = append(urls, s)
+			}
+		}
+	}
+
+	resolved := []primitive.ObjectID{}
+	for _, url := range urls {
+		if id, ok := ids[url]; ok {
+			resolved = append(resolved, id)
+		}
+	}
+	return resolved
+}
+
+func objectIds(value interface{}) []primitive.ObjectID {
+	switch v := value.(type) {
+	case primitive.ObjectID:
+		return []primitive.ObjectID{v}
+	case primitive.A:
+		ids := make([]primitive.ObjectID, 0, len(v))
+		for _, id := range v {
+			if oid, ok := id.(primitive.ObjectID); ok {
+				ids = append(ids, oid)
+			}
+		}
+		return ids
+	}
+	return nil
+}
+
+func sameObjectIds(a, b []primitive.ObjectID) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
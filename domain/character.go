@@ -0,0 +1,89 @@
+package domain
+
+import (
+	"context"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/Kamva/mgm/v2"
+	mongopagination "github.com/gobeam/mongo-go-pagination"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type Character struct {
+	mgm.DefaultModel `bson:",inline"`
+	SourceResource   `bson:",inline"`
+	Name             string               `json:"name" bson:"name"`
+	Height           string               `json:"height" bson:"height"`
+	HeightCm         *int64               `json:"height_cm" bson:"height_cm"`
+	Mass             string               `json:"mass" bson:"mass"`
+	HairColor        string               `json:"hair_color" bson:"hair_color"`
+	SkinColor        string               `json:"skin_color" bson:"skin_color"`
+	EyeColor         string               `json:"eye_color" bson:"eye_color"`
+	BirthYear        string               `json:"birth_year" bson:"birth_year"`
+	Gender           string               `json:"gender" bson:"gender"`
+	Homeworld        string               `json:"homeworld" bson:"homeworld"`
+	HomeworldId      *primitive.ObjectID  `json:"homeworld_id,omitempty" bson:"homeworld_id,omitempty"`
+	Species          []string             `json:"species" bson:"species"`
+	SpeciesIds       []primitive.ObjectID `json:"species_ids,omitempty" bson:"species_ids,omitempty"`
+	Vehicles         []string             `json:"vehicles" bson:"vehicles"`
+	VehicleIds       []primitive.ObjectID `json:"vehicle_ids,omitempty" bson:"vehicle_ids,omitempty"`
+	Starships        []string             `json:"starships" bson:"starships"`
+	StarshipIds      []primitive.ObjectID `json:"starship_ids,omitempty" bson:"starship_ids,omitempty"`
+}
+
+func (m *Character) CollectionName() string {
+	return string(ResourceCharacters)
+}
+
+// ParseHeightCm parses a source height such as "172" into centimetres.
+// Unknown heights return nil.
+func ParseHeightCm(height string) *int64 {
+	cm, err := strconv.ParseFloat(strings.ReplaceAll(strings.TrimSpace(height), ",", ""), 64)
+	if err != nil || cm <= 0 {
+		return nil
+	}
+
+	rounded := int64(math.Round(cm))
+	return &rounded
+}
+
+type CharacterMetadata struct {
+	Count             int64   `json:"count" bson:"count"`
+	TotalHeightCm     int64   `json:"total_height_cm" bson:"total_height_cm"`
+	TotalHeightFeet   int64   `json:"total_height_feet" bson:"total_height_feet"`
+	TotalHeightInches float64 `json:"total_height_inches" bson:"total_height_inches"`
+}
+
+// NewCharacterMetadata returns the metadata of count characters measuring
+// totalHeightCm, with the height also given in feet and inches.
+func NewCharacterMetadata(count, totalHeightCm int64) *CharacterMetadata {
+	inches := float64(totalHeightCm) / 2.54
+	feet := math.Floor(inches / 12)
+
+	return &CharacterMetadata{
+		Count:             count,
+		TotalHeightCm:     totalHeightCm,
+		TotalHeightFeet:   int64(feet),
+		TotalHeightInches: math.Round((inches-feet*12)*100) / 100,
+	}
+}
+
+type PaginatedCharacter struct {
+	Pagination *mongopagination.PaginatedData `json:"pagination" bson:"pagination"`
+	Data       []Character                    `json:"data" bson:"data"`
+	Metadata   *CharacterMetadata             `json:"metadata,omitempty" bson:"metadata,omitempty"`
+}
+
+type FilmCharactersQuery struct {
+	Sort   string `validate:"omitempty,oneof=name height" json:"sort" query:"sort"`
+	Order  string `validate:"omitempty,oneof=asc desc" json:"order" query:"order"`
+	Gender string `validate:"omitempty,oneof=male female hermaphrodite n/a none unknown" json:"gender" query:"gender"`
+}
+
+type CharacterRepository interface {
+	GetById(ctx context.Context, id string) (*Character, error)
+	FetchPaginatedCharacters(ctx context.Context, page, limit int64) (*PaginatedCharacter, error)
+	FetchPaginatedFilmCharacters(ctx context.Context, ids []primitive.ObjectID, query FilmCharactersQuery, page, limit int64) (*PaginatedCharacter, error)
+}
@@ -0,0 +1,36 @@
+package domain
+
+import "testing"
+
+func TestParseHeightCm(t *testing.T) {
+	tests := []struct {
+		height string
+		want   int64
+		ok     bool
+	}{
+		{height: "172", want: 172, ok: true},
+		{height: " 96 ", want: 96, ok: true},
+		{height: "1,300", want: 1300, ok: true},
+		{height: "183.5", want: 184, ok: true},
+		{height: "unknown"},
+		{height: "n/a"},
+		{height: ""},
+		{height: "0"},
+		{height: "-5"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.height, func(t *testing.T) {
+			got := ParseHeightCm(tt.height)
+			if !tt.ok {
+				if got != nil {
+					t.Errorf("got %d, want nil", *got)
+				}
+				return
+			}
+			if got == nil || *got != tt.want {
+				t.Errorf("got %v, want %d", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,107 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"os"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+)
+
+const defaultTokenIssuer = "movies-review-api"
+
+var (
+	ErrTokenSubject  = errors.New("token has no subject")
+	ErrTokenExpiry   = errors.New("token has no expiry")
+	ErrTokenIssuer   = errors.New("token has an unexpected issuer")
+	ErrTokenAudience = errors.New("token has an unexpected audience")
+)
+
+// AuthClaims are the claims of an access token. The user id is the subject
+// and the token id (jti) is used to revoke it. IssuedAtMs is the issue time
+// in milliseconds, iat only has seconds.
+type AuthClaims struct {
+	jwt.StandardClaims
+	Email      string `json:"email,omitempty"`
+	Role       string `json:"role,omitempty"`
+	IssuedAtMs int64  `json:"iat_ms,omitempty"`
+}
+
+// IssuedTime is when the token was issued. Tokens without iat_ms are taken
+// as issued at the start of their iat second, so a revocation in that second
+// still covers them.
+func (c AuthClaims) IssuedTime() time.Time {
+	if c.IssuedAtMs != 0 {
+		return time.UnixMilli(c.IssuedAtMs)
+	}
+	return time.Unix(c.IssuedAt, 0)
+}
+
+// Valid checks the registered time claims and that the token names its
+// user and expires.
+func (c AuthClaims) Valid() error {
+	if err := c.StandardClaims.Valid(); err != nil {
+		return err
+	}
+	if c.Subject == "" {
+		return ErrTokenSubject
+	}
+	if c.ExpiresAt == 0 {
+		return ErrTokenExpiry
+	}
+	return nil
+}
+
+// Validate checks the token was issued by and for the expected parties.
+func (c AuthClaims) Validate(issuer, audience string) error {
+	if !c.VerifyIssuer(issuer, true) {
+		return ErrTokenIssuer
+	}
+	if !c.VerifyAudience(audience, true) {
+		return ErrTokenAudience
+	}
+	return nil
+}
+
+// TokenIssuer is the iss claim of access tokens, JWT_ISSUER or
+// movies-review-api by default.
+func TokenIssuer() string {
+	if issuer := os.Getenv("JWT_ISSUER"); issuer != "" {
+		return issuer
+	}
+	return defaultTokenIssuer
+}
+
+// TokenAudience is the aud claim of access tokens, JWT_AUDIENCE or the
+// issuer by default.
+func TokenAudience() string {
+	if audience := os.Getenv("JWT_AUDIENCE"); audience != "" {
+		return audience
+	}
+	return TokenIssuer()
+}
+
+// Principal is the user authenticated by an access token.
+type Principal struct {
+	UserId string
+	Email  string
+	// Role is the stored role, so role changes apply to issued tokens.
+	Role          string
+	EmailVerified bool
+	TokenId       string
+	ExpiresAt     time.Time
+}
+
+type principalKey struct{}
+
+// WithPrincipal returns a copy of ctx carrying the principal.
+func WithPrincipal(ctx context.Context, principal *Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, principal)
+}
+
+// PrincipalFromContext returns the principal carried by ctx, if any.
+func PrincipalFromContext(ctx context.Context) (*Principal, bool) {
+	principal, ok := ctx.Value(principalKey{}).(*Principal)
+	return principal, ok
+}
@@ -2,42 +2,172 @@ package domain
 
 import (
 	"context"
+	"errors"
 	"github.com/Kamva/mgm/v2"
 	mongopagination "github.com/gobeam/mongo-go-pagination"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
+	"net"
+	"time"
 	//"go.mongodb.org/mongo-driver/bson"
 )
 
+var (
+	ErrCommentForbidden          = errors.New("comment belongs to another user")
+	ErrAnonymousCommentsDisabled = errors.New("anonymous comments are disabled for this film")
+	ErrCommentRejected           = errors.New("comment rejected")
+)
+
+const (
+	CommentSortOldest = "oldest"
+	CommentSortTop    = "top"
+)
+
+const (
+	CommentStatusApproved = "approved"
+	// CommentStatusHeld comments wait for a moderator and are hidden from
+	// the comment lists until approved.
+	CommentStatusHeld = "held"
+	// CommentStatusHidden comments were hidden by a moderator.
+	CommentStatusHidden = "hidden"
+	// CommentStatusReported comments were hidden by reports, dismissing the
+	// reports shows them again.
+	CommentStatusReported = "reported"
+)
+
+// HiddenCommentStatuses are left out of the comment lists.
+var HiddenCommentStatuses = []string{CommentStatusHeld, CommentStatusHidden, CommentStatusReported}
+
 type Comment struct {
-	mgm.DefaultModel `bson:",inline"`
-	FilmId           string `json:"film_id" bson:"film_id"`
-	UserId           string `json:"user_id" bson:"user_id"`
-	Summary          string `json:"summary" bson:"summary"`
+	mgm.DefaultModel  `bson:",inline"`
+	FilmId            string           `json:"film_id" bson:"film_id"`
+	UserId            string           `json:"user_id" bson:"user_id"`
+	Anonymous         bool             `json:"anonymous" bson:"anonymous"`
+	AuthorIp          string           `json:"author_ip,omitempty" bson:"author_ip,omitempty"`
+	Summary           string           `json:"summary" bson:"summary"`
+	Status            string           `json:"status,omitempty" bson:"status,omitempty"`
+	ModerationReasons []string         `json:"moderation_reasons,omitempty" bson:"moderation_reasons,omitempty"`
+	ReportCount       int64            `json:"report_count,omitempty" bson:"report_count"`
+	ParentId          string           `json:"parent_id,omitempty" bson:"parent_id,omitempty"`
+	Depth             int64            `json:"depth" bson:"depth"`
+	RepliesCount      int64            `json:"replies_count" bson:"replies_count"`
+	ReactionCounts    map[string]int64 `json:"reaction_counts,omitempty" bson:"reaction_counts,omitempty"`
+	ReactionScore     int64            `json:"reaction_score" bson:"reaction_score"`
+	EditedAt          *time.Time       `json:"edited_at,omitempty" bson:"edited_at"`
+	EditedBy          string           `json:"edited_by,omitempty" bson:"edited_by,omitempty"`
+	Revision          int64            `json:"revision" bson:"revision,omitempty"`
+	DeletedAt         *time.Time       `json:"deleted_at,omitempty" bson:"deleted_at"`
 }
 
 type PaginatedComment struct {
-	Pagination *mongopagination.PaginatedData `json:"pagination" bson:"pagination"`
+	Pagination *mongopagination.PaginatedData `json:"pagination,omitempty" bson:"pagination,omitempty"`
+	Cursor     *CursorPagination              `json:"cursor,omitempty" bson:"cursor,omitempty"`
 	Data       []Comment                      `json:"data" bson:"data"`
 }
 
 type NewCommentRequest struct {
-	FilmId  string `validate:"required" json:"film_id" bson:"film_id"`
-	UserId  string `json:"user_id" bson:"user_id"`
+	FilmId        string `validate:"required" json:"film_id" bson:"film_id"`
+	UserId        string `json:"user_id" bson:"user_id"`
+	Summary       string `validate:"required,max=500" json:"summary" bson:"summary"`
+	ParentId      string `json:"parent_id,omitempty" bson:"parent_id,omitempty"`
+	AuthorIp      string `json:"-" bson:"-"`
+	EmailVerified bool   `json:"-" bson:"-"`
+}
+
+type UpdateCommentRequest struct {
 	Summary string `validate:"required,max=500" json:"summary" bson:"summary"`
 }
 
 type CommentRepository interface {
 	Create(ctx context.Context, comment *Comment) (*Comment, error)
-	FetchPaginatedFilmComments(ctx context.Context, filmId string, page, limit int64) (*PaginatedComment, error)
+	GetById(ctx context.Context, id string) (*Comment, error)
+	Update(ctx context.Context, comment *Comment) (*Comment, error)
+	SoftDelete(ctx context.Context, comment *Comment) error
+	FetchPaginatedFilmComments(ctx context.Context, filmId, sort string, page, limit int64) (*PaginatedComment, error)
+	FetchCursorFilmComments(ctx context.Context, filmId string, cursor *Cursor, limit int64) (*PaginatedComment, error)
+	FetchPaginatedCommentReplies(ctx context.Context, parentId string, page, limit int64) (*PaginatedComment, error)
+	FetchPaginatedHeldComments(ctx context.Context, page, limit int64) (*PaginatedComment, error)
+	FetchPaginatedReportedComments(ctx context.Context, page, limit int64) (*PaginatedComment, error)
+	// HasRecentDuplicate reports whether the author of the comment posted the
+	// same summary since the given time.
+	HasRecentDuplicate(ctx context.Context, comment *Comment, since time.Time) (bool, error)
+	Approve(ctx context.Context, comment *Comment) error
+	Hold(ctx context.Context, comment *Comment, reasons []string) error
+	Hide(ctx context.Context, comment *Comment) error
+	HideReported(ctx context.Context, comment *Comment) error
 }
 
 type CommentUsecase interface {
 	AddComment(ctx context.Context, reqBody *NewCommentRequest) (*Comment, error)
+	UpdateComment(ctx context.Context, id, userId string, reqBody *UpdateCommentRequest) (*Comment, error)
+	DeleteComment(ctx context.Context, id, userId string) error
+	FetchCommentRevisions(ctx context.Context, id, userId, role string, page, limit int64) (*PaginatedCommentRevision, error)
+	FetchCommentReplies(ctx context.Context, id string, page, limit int64) (*PaginatedComment, error)
+	AddReaction(ctx context.Context, id, userId, reactionType string) (*Reaction, error)
+	RemoveReaction(ctx context.Context, id, userId, reactionType string) error
+	FetchCommentReactions(ctx context.Context, id, reactionType string, page, limit int64) (*PaginatedReaction, error)
+}
+
+// Public returns the comment as shown to everyone but moderators, with the
+// author ip masked.
+func (m Comment) Public() Comment {
+	m.AuthorIp = MaskIP(m.AuthorIp)
+	return m
+}
+
+// MaskIP hides the host part of an ip, keeping the /24 network of an IPv4
+// address and the /48 network of an IPv6 address.
+func MaskIP(ip string) string {
+	parsed := net.ParseIP(ip)
+	if parsed == nil {
+		return ""
+	}
+
+	if v4 := parsed.To4(); v4 != nil {
+		return v4.Mask(net.CIDRMask(24, 32)).String() + "/24"
+	}
+
+	return parsed.Mask(net.CIDRMask(48, 128)).String() + "/48"
+}
+
+// IsHeld reports whether the comment waits for a moderator.
+func (m Comment) IsHeld() bool {
+	return m.Status == CommentStatusHeld
+}
+
+// IsVisible reports whether the comment shows in the comment lists.
+func (m Comment) IsVisible() bool {
+	for _, status := range HiddenCommentStatuses {
+		if m.Status == status {
+			return false
+		}
+	}
+	return true
 }
 
 // After Create Hook. Inherited from mgm.CreateWithCtx
 func (m Comment) Created() error {
+	if m.IsVisible() {
+		if err := m.Published(); err != nil {
+			return err
+		}
+	}
+
+	// the original summary is the first revision
+	return SaveCommentRevision(context.Background(), m.ID.Hex(), 1, m.Summary, m.UserId, m.CreatedAt)
+}
+
+// Published counts the comment on its film and parent once it is visible.
+func (m Comment) Published() error {
+	return m.incCounts(1)
+}
+
+// Unpublished undoes Published once the comment is hidden again.
+func (m Comment) Unpublished() error {
+	return m.incCounts(-1)
+}
+
+func (m Comment) incCounts(n int64) error {
 	filmId, err := primitive.ObjectIDFromHex(m.FilmId)
 	if err != nil {
 		return err
@@ -46,11 +176,38 @@ func (m Comment) Created() error {
 	_, err = mgm.Coll(&Film{}).UpdateOne(
 		context.Background(),
 		bson.M{"_id": filmId},
-		bson.M{"$inc": bson.M{"comment_count": 1}})
+		bson.M{"$inc": bson.M{"comment_count": n}})
 
 	if err != nil {
 		return err
 	}
 
-	return nil
+	return m.incParentReplies(n)
+}
+
+// SoftDeleted undoes the Created hook once the comment is soft deleted.
+func (m Comment) SoftDeleted() error {
+	if !m.IsVisible() {
+		return nil
+	}
+	return m.Unpublished()
+}
+
+// incParentReplies keeps the replies_count of the parent comment, if any, in sync.
+func (m Comment) incParentReplies(n int64) error {
+	if m.ParentId == "" {
+		return nil
+	}
+
+	parentId, err := primitive.ObjectIDFromHex(m.ParentId)
+	if err != nil {
+		return err
+	}
+
+	_, err = mgm.Coll(&Comment{}).UpdateOne(
+		context.Background(),
+		bson.M{"_id": parentId},
+		bson.M{"$inc": bson.M{"replies_count": n}})
+
+	return err
 }
@@ -0,0 +1,97 @@
+package domain
+
+import (
+	"context"
+	"time"
+
+	"github.com/Kamva/mgm/v2"
+	mongopagination "github.com/gobeam/mongo-go-pagination"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// CommentRevision is an immutable copy of a comment summary, saved every
+// time the summary changes.
+type CommentRevision struct {
+	mgm.DefaultModel `bson:",inline"`
+	CommentId        string    `json:"comment_id" bson:"comment_id"`
+	Revision         int64     `json:"revision" bson:"revision"`
+	Summary          string    `json:"summary" bson:"summary"`
+	EditorId         string    `json:"editor_id" bson:"editor_id"`
+	EditedAt         time.Time `json:"edited_at" bson:"edited_at"`
+}
+
+type PaginatedCommentRevision struct {
+	Pagination *mongopagination.PaginatedData `json:"pagination" bson:"pagination"`
+	Data       []CommentRevision              `json:"data" bson:"data"`
+}
+
+type CommentRevisionRepository interface {
+	FetchPaginatedCommentRevisions(ctx context.Context, commentId string, page, limit int64) (*PaginatedCommentRevision, error)
+}
+
+// latestCommentRevision returns the last revision of a comment, or nil when
+// the comment has none yet.
+func latestCommentRevision(ctx context.Context, commentId string) (*CommentRevision, error) {
+	var revision CommentRevision
+
+	err := mgm.Coll(&revision).FirstWithCtx(ctx, bson.M{"comment_id": commentId}, &revision,
+		options.FindOne().SetSort(bson.D{{Key: "revision", Value: -1}}))
+	if err == mongo.ErrNoDocuments {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &revision, nil
+}
+
+// BackfillCommentRevision numbers the revisions of a comment saved before
+// the comment counted them, saving its current summary as the first
+// revision when it has none.
+func BackfillCommentRevision(ctx context.Context, commentId primitive.ObjectID) error {
+	unnumbered := bson.M{"_id": commentId, "revision": bson.M{"$exists": false}}
+
+	var saved Comment
+	err := mgm.Coll(&saved).FirstWithCtx(ctx, unnumbered, &saved)
+	if err == mongo.ErrNoDocuments {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	latest, err := latestCommentRevision(ctx, commentId.Hex())
+	if err != nil {
+		return err
+	}
+
+	revision := int64(1)
+	if latest != nil {
+		revision = latest.Revision
+	} else {
+		err = SaveCommentRevision(ctx, saved.ID.Hex(), revision, saved.Summary, saved.UserId, saved.CreatedAt)
+		// a concurrent edit saved it first
+		if err != nil && !mongo.IsDuplicateKeyError(err) {
+			return err
+		}
+	}
+
+	_, err = mgm.Coll(&saved).UpdateOne(ctx, unnumbered, bson.M{"$set": bson.M{"revision": revision}})
+	return err
+}
+
+// SaveCommentRevision saves revision number revision of a comment summary.
+// The number is reserved on the comment first, so revisions never collide.
+func SaveCommentRevision(ctx context.Context, commentId string, revision int64, summary, editorId string, editedAt time.Time) error {
+	return mgm.Coll(&CommentRevision{}).CreateWithCtx(ctx, &CommentRevision{
+		CommentId: commentId,
+		Revision:  revision,
+		Summary:   summary,
+		EditorId:  editorId,
+		EditedAt:  editedAt,
+	})
+}
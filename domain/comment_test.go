@@ -0,0 +1,26 @@
+package domain
+
+import "testing"
+
+func TestMaskIP(t *testing.T) {
+	tests := []struct {
+		ip   string
+		want string
+	}{
+		{ip: "203.0.113.42", want: "203.0.113.0/24"},
+		{ip: "10.1.2.3", want: "10.1.2.0/24"},
+		{ip: "::ffff:203.0.113.42", want: "203.0.113.0/24"},
+		{ip: "2001:db8:abcd:12:1:2:3:4", want: "2001:db8:abcd::/48"},
+		{ip: "::1", want: "::/48"},
+		{ip: "", want: ""},
+		{ip: "not an ip", want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.ip, func(t *testing.T) {
+			if got := MaskIP(tt.ip); got != tt.want {
+				t.Errorf("MaskIP(%q) = %q, want %q", tt.ip, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,52 @@
+package domain
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// Cursor points at a document in created_at, _id order. It is handed to
+// clients as an opaque string.
+type Cursor struct {
+	CreatedAt time.Time          `json:"c"`
+	Id        primitive.ObjectID `json:"i"`
+	// Before reads the page before the document instead of the one after it.
+	Before bool `json:"b,omitempty"`
+}
+
+func (c Cursor) Encode() string {
+	cursorJSON, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(cursorJSON)
+}
+
+// DecodeCursor decodes a cursor string. An empty string returns a nil
+// cursor, which starts at the first page.
+func DecodeCursor(s string) (*Cursor, error) {
+	if s == "" {
+		return nil, nil
+	}
+
+	cursorJSON, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	var c Cursor
+	if err = json.Unmarshal(cursorJSON, &c); err != nil || c.Id.IsZero() {
+		return nil, ErrInvalidCursor
+	}
+
+	return &c, nil
+}
+
+type CursorPagination struct {
+	Limit      int64  `json:"limit" bson:"limit"`
+	NextCursor string `json:"next_cursor,omitempty" bson:"next_cursor,omitempty"`
+	PrevCursor string `json:"prev_cursor,omitempty" bson:"prev_cursor,omitempty"`
+}
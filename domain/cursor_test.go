@@ -0,0 +1,57 @@
+package domain
+
+import (
+	"encoding/base64"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestCursorRoundTrip(t *testing.T) {
+	tests := []Cursor{
+		{CreatedAt: time.Date(2023, 5, 1, 12, 0, 0, 123000000, time.UTC), Id: primitive.NewObjectID()},
+		{CreatedAt: time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC), Id: primitive.NewObjectID(), Before: true},
+	}
+
+	for _, want := range tests {
+		got, err := DecodeCursor(want.Encode())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !got.CreatedAt.Equal(want.CreatedAt) || got.Id != want.Id || got.Before != want.Before {
+			t.Errorf("got %+v, want %+v", *got, want)
+		}
+	}
+}
+
+func TestDecodeCursor(t *testing.T) {
+	tests := []struct {
+		name    string
+		cursor  string
+		wantNil bool
+		wantErr bool
+	}{
+		{name: "empty", cursor: "", wantNil: true},
+		{name: "not base64", cursor: "!!!", wantNil: true, wantErr: true},
+		{name: "not json", cursor: base64.RawURLEncoding.EncodeToString([]byte("cursor")), wantNil: true, wantErr: true},
+		{name: "no id", cursor: base64.RawURLEncoding.EncodeToString([]byte(`{"c":"2023-05-01T12:00:00Z"}`)), wantNil: true, wantErr: true},
+		{name: "padded", cursor: base64.URLEncoding.EncodeToString([]byte(`{"i":"644f9b0c8f1b2c3d4e5f6a7b"}`)), wantNil: true, wantErr: true},
+		{name: "valid", cursor: base64.RawURLEncoding.EncodeToString([]byte(`{"i":"644f9b0c8f1b2c3d4e5f6a7b"}`))},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := DecodeCursor(tt.cursor)
+			if tt.wantErr && err != ErrInvalidCursor {
+				t.Errorf("err = %v, want %v", err, ErrInvalidCursor)
+			}
+			if !tt.wantErr && err != nil {
+				t.Errorf("err = %v", err)
+			}
+			if (got == nil) != tt.wantNil {
+				t.Errorf("got %v, wantNil %v", got, tt.wantNil)
+			}
+		})
+	}
+}
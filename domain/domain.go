@@ -1,6 +1,8 @@
 package domain
 
 import (
+	"crypto/rand"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"github.com/dgrijalva/jwt-go"
@@ -9,7 +11,9 @@ import (
 	"github.com/joho/godotenv"
 	"go.uber.org/zap"
 	"golang.org/x/crypto/bcrypt"
-	"os"
+	"reflect"
+	"sort"
+	"strings"
 	"time"
 )
 
@@ -43,18 +47,33 @@ func GetSecrets(logger *zap.Logger) {
 
 }
 
-func GenerateToken(user User) (string, error) {
-	usr := map[string]interface{}{
-		"email": user.Email,
-		"_id":   user.ID.Hex(),
+func GenerateToken(keys *KeySet, user User) (string, error) {
+	role := user.Role
+	if role == "" {
+		role = RoleUser
 	}
-	token := jwt.New(jwt.SigningMethodHS256)
 
-	claims := token.Claims.(jwt.MapClaims)
-	claims["user"] = usr
-	claims["exp"] = time.Now().Add(time.Hour * 24).Unix()
+	jti, err := newJti()
+	if err != nil {
+		return "", err
+	}
 
-	t, err := token.SignedString([]byte(os.Getenv("JWT_SECRET_KEY")))
+	now := time.Now()
+
+	t, err := keys.Sign(AuthClaims{
+		StandardClaims: jwt.StandardClaims{
+			Id:        jti,
+			Subject:   user.ID.Hex(),
+			Issuer:    TokenIssuer(),
+			Audience:  TokenAudience(),
+			IssuedAt:  now.Unix(),
+			NotBefore: now.Unix(),
+			ExpiresAt: now.Add(AccessTokenTTL()).Unix(),
+		},
+		Email:      user.Email,
+		Role:       role,
+		IssuedAtMs: now.UnixMilli(),
+	})
 
 	if err != nil {
 		return "", err
@@ -63,6 +82,15 @@ func GenerateToken(user User) (string, error) {
 	return t, nil
 }
 
+// newJti returns a random token id, used to revoke a single token.
+func newJti() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
 func CheckPasswordHash(password, hash string) bool {
 	err := bcrypt.CompareHashAndPassword([]byte(hash), []byte(password))
 	return err == nil
@@ -89,6 +117,65 @@ func HandleValidationError(c *fiber.Ctx, err error) error {
 	return HandleError(c, errors.New(errMessage))
 }
 
+// FieldErrors maps the name of each invalid field to what is wrong with it.
+type FieldErrors map[string]string
+
+func (e FieldErrors) Error() string {
+	fields := make([]string, 0, len(e))
+	for field, msg := range e {
+		fields = append(fields, fmt.Sprintf("%s %s", field, msg))
+	}
+	sort.Strings(fields)
+	return strings.Join(fields, ", ")
+}
+
+// NewFieldErrors converts the errors returned by validator into FieldErrors.
+func NewFieldErrors(err error) FieldErrors {
+	errs := FieldErrors{}
+
+	validationErrors, ok := err.(validator.ValidationErrors)
+	if !ok {
+		errs["_"] = err.Error()
+		return errs
+	}
+
+	for _, e := range validationErrors {
+		switch e.Tag() {
+		case "oneof":
+			errs[e.Field()] = fmt.Sprintf("must be one of %s", e.Param())
+		case "datetime":
+			errs[e.Field()] = fmt.Sprintf("must be a date formatted as %s", e.Param())
+		case "number":
+			errs[e.Field()] = "must be a positive whole number"
+		case "min", "max":
+			bound := "at least"
+			if e.Tag() == "max" {
+				bound = "at most"
+			}
+			if e.Kind() == reflect.String {
+				errs[e.Field()] = fmt.Sprintf("must be %s %s characters", bound, e.Param())
+			} else {
+				errs[e.Field()] = fmt.Sprintf("must be %s %s", bound, e.Param())
+			}
+		case "required":
+			errs[e.Field()] = "is required"
+		default:
+			errs[e.Field()] = fmt.Sprintf("failed %s validation", e.Tag())
+		}
+	}
+
+	return errs
+}
+
+func HandleFieldErrors(c *fiber.Ctx, errs FieldErrors) error {
+	return c.Status(400).JSON(
+		fiber.Map{
+			"error":  true,
+			"msg":    errs.Error(),
+			"errors": errs,
+		})
+}
+
 type Config struct {
 	// Filter defines a function to skip middleware.
 	// Optional. Default: nil
@@ -120,9 +207,10 @@ type Config struct {
 	// Optional. Default: "user".
 	ContextKey string
 
-	// Claims are extendable claims data defining token content.
-	// Optional. Default value jwt.MapClaims
-	Claims jwt.Claims
+	// Issuer and Audience are the expected iss and aud claims.
+	// Required.
+	Issuer   string
+	Audience string
 
 	// TokenLookup is a string in the form of "<source>:<name>" that is used
 	// to extract token from the request.
@@ -141,4 +229,12 @@ type Config struct {
 	KeyFunc jwt.Keyfunc
 
 	ValidatorFunction UserRepository
+
+	// RevocationStore rejects revoked tokens.
+	// Optional. Default: nil
+	RevocationStore RevocationStore
+
+	// Logger logs the errors of the revocation store.
+	// Optional. Default: zap.NewNop()
+	Logger *zap.Logger
 }
@@ -0,0 +1,66 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"github.com/Kamva/mgm/v2"
+	"time"
+)
+
+var (
+	ErrUserNotFound             = errors.New("user not found")
+	ErrInvalidVerificationToken = errors.New("invalid or expired verification token")
+	ErrEmailAlreadyVerified     = errors.New("email already verified")
+	ErrEmailNotVerified         = errors.New("verify your email to comment")
+	ErrVerificationEmailNotSent = errors.New("verification email could not be sent")
+)
+
+const defaultEmailVerificationTTL = 24 * time.Hour
+
+// EmailVerification is a token sent to a user to prove they own their
+// email. Only the hash of the token is stored.
+type EmailVerification struct {
+	mgm.DefaultModel `bson:",inline"`
+	UserId           string     `json:"user_id" bson:"user_id"`
+	Email            string     `json:"email" bson:"email"`
+	TokenHash        string     `json:"-" bson:"token_hash"`
+	ExpiresAt        time.Time  `json:"expires_at" bson:"expires_at"`
+	UsedAt           *time.Time `json:"used_at,omitempty" bson:"used_at,omitempty"`
+}
+
+type VerifyEmailRequest struct {
+	Token string `validate:"required" json:"token"`
+}
+
+type EmailVerificationRepository interface {
+	Create(ctx context.Context, verification *EmailVerification) (*EmailVerification, error)
+	GetByHash(ctx context.Context, tokenHash string) (*EmailVerification, error)
+	// MarkUsed marks the token used, false when it already was.
+	MarkUsed(ctx context.Context, verification *EmailVerification) (bool, error)
+}
+
+// Message is an email sent by a Mailer.
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Mailer sends emails.
+type Mailer interface {
+	Send(ctx context.Context, message Message) error
+}
+
+func NewVerificationToken() (string, string, error) {
+	return newSecretToken()
+}
+
+func HashVerificationToken(token string) string {
+	return hashSecretToken(token)
+}
+
+// EmailVerificationTTL is how long verification tokens are valid,
+// EMAIL_VERIFICATION_TTL or 24 hours by default.
+func EmailVerificationTTL() time.Duration {
+	return durationEnv("EMAIL_VERIFICATION_TTL", defaultEmailVerificationTTL)
+}
@@ -5,132 +5,424 @@ import (
 	"crypto/md5"
 	"encoding/hex"
 	"encoding/json"
+	"fmt"
 	"github.com/Kamva/mgm/v2"
+	"github.com/go-playground/validator/v10"
 	mongopagination "github.com/gobeam/mongo-go-pagination"
 	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
 	"go.uber.org/zap"
-	"net/http"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
 	//"go.mongodb.org/mongo-driver/bson"
 )
 
 type Film struct {
 	mgm.DefaultModel `bson:",inline"`
-	Title            string `json:"title" bson:"title"`
-	CommentCount     int64  `json:"comment_count" bson:"comment_count"`
-	ReleaseDate      string `json:"release_date" bson:"release_date"`
+	Title            string               `json:"title" bson:"title"`
+	CommentCount     int64                `json:"comment_count" bson:"comment_count"`
+	ReviewCount      int64                `json:"review_count" bson:"review_count"`
+	RatingCount      int64                `json:"rating_count" bson:"rating_count"`
+	RatingSum        int64                `json:"-" bson:"rating_sum"`
+	RatingAvg        float64              `json:"rating_avg" bson:"rating_avg"`
+	ReleaseDate      string               `json:"release_date" bson:"release_date"`
+	EpisodeId        int64                `json:"episode_id" bson:"episode_id"`
+	OpeningCrawl     string               `json:"opening_crawl" bson:"opening_crawl"`
+	Director         string               `json:"director" bson:"director"`
+	Producer         string               `json:"producer" bson:"producer"`
+	Characters       []string             `json:"characters" bson:"characters"`
+	Planets          []string             `json:"planets" bson:"planets"`
+	Starships        []string             `json:"starships" bson:"starships"`
+	Vehicles         []string             `json:"vehicles" bson:"vehicles"`
+	Species          []string             `json:"species" bson:"species"`
+	CharacterIds     []primitive.ObjectID `json:"character_ids,omitempty" bson:"character_ids,omitempty"`
+	PlanetIds        []primitive.ObjectID `json:"planet_ids,omitempty" bson:"planet_ids,omitempty"`
+	StarshipIds      []primitive.ObjectID `json:"starship_ids,omitempty" bson:"starship_ids,omitempty"`
+	VehicleIds       []primitive.ObjectID `json:"vehicle_ids,omitempty" bson:"vehicle_ids,omitempty"`
+	SpeciesIds       []primitive.ObjectID `json:"species_ids,omitempty" bson:"species_ids,omitempty"`
+	SourceCreatedAt  time.Time            `json:"source_created_at" bson:"source_created_at"`
+	SourceEditedAt   time.Time            `json:"source_edited_at" bson:"source_edited_at"`
+	Source           string               `json:"source" bson:"source"`
+	ExternalId       string               `json:"external_id" bson:"external_id"`
+	RetiredAt        *time.Time           `json:"retired_at,omitempty" bson:"retired_at"`
+	// AllowAnonymousComments opts the film into anonymous comments when they
+	// are not enabled globally.
+	AllowAnonymousComments bool `json:"allow_anonymous_comments" bson:"allow_anonymous_comments"`
+}
+
+// FilmFields are the fields a film list can be projected on.
+var FilmFields = []string{
+	"_id", "created_at", "updated_at", "title", "comment_count", "review_count", "rating_count",
+	"rating_avg", "release_date",
+	"episode_id", "opening_crawl", "director", "producer", "characters", "planets",
+	"starships", "vehicles", "species", "character_ids", "planet_ids", "starship_ids",
+	"vehicle_ids", "species_ids", "source_created_at", "source_edited_at",
+	"source", "external_id", "retired_at", "allow_anonymous_comments",
+}
+
+// ParseFilmFields parses a comma separated fields query into a list of
+// FilmFields. The _id field is always included and repeated fields are
+// listed once.
+func ParseFilmFields(raw string) ([]string, error) {
+	if strings.TrimSpace(raw) == "" {
+		return nil, nil
+	}
+
+	fields := []string{"_id"}
+	seen := map[string]bool{"_id": true}
+	for _, field := range strings.Split(raw, ",") {
+		field = strings.TrimSpace(field)
+		if field == "" || seen[field] {
+			continue
+		}
+		seen[field] = true
+
+		valid := false
+		for _, f := range FilmFields {
+			if f == field {
+				valid = true
+				break
+			}
+		}
+		if !valid {
+			return nil, fmt.Errorf("unknown film field %s", field)
+		}
+
+		fields = append(fields, field)
+	}
+
+	return fields, nil
+}
+
+// FilmQuery filters, sorts and projects a film list.
+type FilmQuery struct {
+	// Title matches films whose title contains it, case insensitive.
+	Title string `validate:"omitempty,max=100" json:"title" query:"title"`
+	// Search is a full-text search on the title and opening crawl.
+	Search       string   `validate:"omitempty,max=100" json:"search" query:"search"`
+	ReleasedFrom string   `validate:"omitempty,datetime=2006-01-02" json:"released_from" query:"released_from"`
+	ReleasedTo   string   `validate:"omitempty,datetime=2006-01-02" json:"released_to" query:"released_to"`
+	Director     string   `validate:"omitempty,max=100" json:"director" query:"director"`
+	MinComments  string   `validate:"omitempty,number" json:"min_comments" query:"min_comments"`
+	Sort         string   `validate:"omitempty,oneof=title release_date comment_count rating_avg rating_count" json:"sort" query:"sort"`
+	Order        string   `validate:"omitempty,oneof=asc desc" json:"order" query:"order"`
+	Fields       []string `json:"fields" query:"-"`
+}
+
+// Validate checks the query and returns the errors of every invalid field.
+func (q FilmQuery) Validate(validate *validator.Validate) FieldErrors {
+	errs := FieldErrors{}
+	if err := validate.Struct(q); err != nil {
+		errs = NewFieldErrors(err)
+	}
+
+	if _, ok := errs["released_to"]; !ok && q.ReleasedFrom != "" && q.ReleasedTo != "" && q.ReleasedTo < q.ReleasedFrom {
+		errs["released_to"] = "must not be before released_from"
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// Project returns the film as a map holding only the given fields.
+func (m Film) Project(fields []string) (map[string]interface{}, error) {
+	filmJSON, err := json.Marshal(m)
+	if err != nil {
+		return nil, err
+	}
+
+	var all map[string]interface{}
+	if err = json.Unmarshal(filmJSON, &all); err != nil {
+		return nil, err
+	}
+
+	projected := make(map[string]interface{}, len(fields))
+	for _, field := range fields {
+		if value, ok := all[field]; ok {
+			projected[field] = value
+		}
+	}
+
+	return projected, nil
+}
+
+// sourceFields returns the fields of a film that are owned by its source.
+func (m *Film) sourceFields() bson.M {
+	return bson.M{
+		"title":             m.Title,
+		"release_date":      m.ReleaseDate,
+		"episode_id":        m.EpisodeId,
+		"opening_crawl":     m.OpeningCrawl,
+		"director":          m.Director,
+		"producer":          m.Producer,
+		"characters":        m.Characters,
+		"planets":           m.Planets,
+		"starships":         m.Starships,
+		"vehicles":          m.Vehicles,
+		"species":           m.Species,
+		"source_created_at": m.SourceCreatedAt,
+		"source_edited_at":  m.SourceEditedAt,
+		"source":            m.Source,
+		"external_id":       m.ExternalId,
+		"retired_at":        m.RetiredAt,
+	}
+}
+
+// sameSourceField reports whether two values of a source field are equal.
+// Times are compared at the millisecond precision mongo stores them with.
+func sameSourceField(a, b interface{}) bool {
+	if at, ok := a.(time.Time); ok {
+		if bt, ok := b.(time.Time); ok {
+			return at.Truncate(time.Millisecond).Equal(bt.Truncate(time.Millisecond))
+		}
+	}
+	if as, ok := a.([]string); ok {
+		if bs, ok := b.([]string); ok && len(as) == 0 && len(bs) == 0 {
+			return true
+		}
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+// FilmChange is a single field of a film changed by a sync.
+type FilmChange struct {
+	FilmId     primitive.ObjectID `json:"film_id" bson:"film_id"`
+	ExternalId string             `json:"external_id" bson:"external_id"`
+	Field      string             `json:"field" bson:"field"`
+	Old        interface{}        `json:"old" bson:"old"`
+	New        interface{}        `json:"new" bson:"new"`
 }
 
 type PaginatedFilm struct {
-	Pagination *mongopagination.PaginatedData `json:"pagination" bson:"pagination"`
+	Pagination *mongopagination.PaginatedData `json:"pagination,omitempty" bson:"pagination,omitempty"`
+	Cursor     *CursorPagination              `json:"cursor,omitempty" bson:"cursor,omitempty"`
 	Data       []Film                         `json:"data" bson:"data"`
 }
 
+// StarwarsDataHash is the content hash of one page of one FilmSource.
 type StarwarsDataHash struct {
 	mgm.DefaultModel `bson:",inline"`
+	Source           string `json:"source" bson:"source"`
+	PageUrl          string `json:"page_url" bson:"page_url"`
 	Hash             string `json:"hash" bson:"hash"`
 	Count            int64  `json:"count" bson:"count"`
 }
 
-type StarwarsApiResponse struct {
-	Next     string `json:"next" bson:"next"`
-	Previous string `json:"previous" bson:"previous"`
-	Count    int64  `json:"count" bson:"count"`
-	Results  []Film `json:"results" bson:"results"`
-}
+// UpdateFilmFromSource walks every page of the given source and upserts its
+// films on their ExternalId. Pages whose hash did not change are skipped, and
+// films that are gone from the source are marked as retired once every page
+// was read. Page, film, hash and diff statistics are collected on run.
+func UpdateFilmFromSource(ctx context.Context, logger *zap.Logger, source FilmSource, run *SyncRun) error {
+	var (
+		pageUrl      string
+		hashesBefore []string
+		hashesAfter  []string
+		externalIds  []string
+	)
+
+	for {
+		// Make a request to the external API and retrieve the new data
+		data, err := source.FetchPage(ctx, pageUrl)
+		if err != nil {
+			return err
+		}
+		run.PagesFetched++
 
-func UpdateFilmFromSource(logger *zap.Logger, api string) error {
-	// Make a request to the external API and retrieve the new data
+		for _, sourceFilm := range data.Results {
+			externalIds = append(externalIds, sourceFilm.ExternalId)
+		}
 
-	if api == "" {
-		api = "https://swapi.dev/api/films/"
+		// fetch last saved hash of this page
+		savedData, err := findStarwarsDataHash(ctx, source.Name(), data.Url, run.PagesFetched == 1)
+		if err != nil {
+			return err
+		}
+
+		// hash results
+		newHash, err := hashStarwarsApiData(data.Results)
+		if err != nil {
+			return err
+		}
+
+		hashesBefore = append(hashesBefore, savedData.Hash)
+		hashesAfter = append(hashesAfter, newHash)
+		if run.HashBefore, err = hashStarwarsApiData(hashesBefore); err != nil {
+			return err
+		}
+		if run.HashAfter, err = hashStarwarsApiData(hashesAfter); err != nil {
+			return err
+		}
+
+		// update db with new data
+		if savedData.Hash == newHash {
+			run.FilmsUnchanged += int64(len(data.Results))
+		} else {
+			failed := false
+			for i := range data.Results {
+				if err = upsertSourceFilm(ctx, &data.Results[i], run); err != nil {
+					logger.Error("Error:", zap.Error(err))
+					run.Errors = append(run.Errors, fmt.Sprintf("film %s: %v", data.Results[i].ExternalId, err))
+					failed = true
+				}
+			}
+
+			// the page is read again next run until every film of it is saved
+			if !failed {
+				savedData.Count = int64(len(data.Results))
+				if err = saveStarwarsDataHash(ctx, savedData, newHash); err != nil {
+					return err
+				}
+			}
+		}
+
+		// go to next page
+		if data.Next == "" {
+			break
+		}
+		pageUrl = data.Next
 	}
 
-	resp, err := http.Get(api)
+	return retireSourceFilms(ctx, source.Name(), externalIds, run)
+}
+
+// upsertSourceFilm creates the source film, or updates the saved film with the
+// same ExternalId when any of its source fields changed.
+func upsertSourceFilm(ctx context.Context, sourceFilm *Film, run *SyncRun) error {
+	coll := mgm.Coll(&Film{})
+
+	var film Film
+	err := coll.FirstWithCtx(ctx, bson.M{"source": sourceFilm.Source, "external_id": sourceFilm.ExternalId}, &film)
+	if err == mongo.ErrNoDocuments {
+		// films saved before they were keyed on the source are matched on title once
+		err = coll.FirstWithCtx(ctx, bson.M{"title": sourceFilm.Title, "external_id": bson.M{"$in": bson.A{nil, ""}}}, &film)
+	}
+	if err == mongo.ErrNoDocuments {
+		if err = coll.CreateWithCtx(ctx, sourceFilm); err != nil {
+			return err
+		}
+		run.FilmsInserted++
+		return nil
+	}
 	if err != nil {
 		return err
 	}
-	defer resp.Body.Close()
 
-	// Parse the JSON response into a new struct
-	var data StarwarsApiResponse
-	if err := json.NewDecoder(resp.Body).Decode(&data); err != nil {
-		return err
+	oldFields := film.sourceFields()
+	newFields := sourceFilm.sourceFields()
+
+	fields := make([]string, 0, len(newFields))
+	for field := range newFields {
+		fields = append(fields, field)
 	}
+	sort.Strings(fields)
 
-	var (
-		savedDataArr []StarwarsDataHash
-		savedData    StarwarsDataHash
-	)
+	update := bson.M{}
+	for _, field := range fields {
+		if sameSourceField(oldFields[field], newFields[field]) {
+			continue
+		}
+		update[field] = newFields[field]
+		run.Changes = append(run.Changes, FilmChange{
+			FilmId:     film.ID,
+			ExternalId: sourceFilm.ExternalId,
+			Field:      field,
+			Old:        oldFields[field],
+			New:        newFields[field],
+		})
+	}
 
-	// fetch last saved hash
-	err = mgm.Coll(&StarwarsDataHash{}).SimpleFind(&savedDataArr, bson.M{})
-	if err != nil {
+	if len(update) == 0 {
+		run.FilmsUnchanged++
+		return nil
+	}
+
+	update["updated_at"] = time.Now().UTC()
+	if _, err = coll.UpdateByID(ctx, film.ID, bson.M{"$set": update}); err != nil {
 		return err
 	}
+	run.FilmsUpdated++
+	return nil
+}
 
-	if len(savedDataArr) > 0 {
-		savedData = savedDataArr[0]
+// retireSourceFilms marks the films of a source that are not in externalIds as retired.
+func retireSourceFilms(ctx context.Context, source string, externalIds []string, run *SyncRun) error {
+	if externalIds == nil {
+		externalIds = []string{}
 	}
 
-	// hash results
-	newHash, err := hashStarwarsApiData(data.Results)
+	now := time.Now().UTC()
+	result, err := mgm.Coll(&Film{}).UpdateMany(ctx, bson.M{
+		"source":      source,
+		"external_id": bson.M{"$nin": externalIds},
+		"retired_at":  nil,
+	}, bson.M{"$set": bson.M{"retired_at": now, "updated_at": now}})
 	if err != nil {
 		return err
 	}
 
-	// if no new data
-	noNewData, err := compareStarwarsDataHash(&savedData, newHash)
-	if err != nil {
-		return err
+	run.FilmsRetired = result.ModifiedCount
+	return nil
+}
+
+// findStarwarsDataHash returns the saved hash of a source page, or an unsaved
+// one when the page was never hashed. The single hash document stored before
+// hashes were keyed by source and page is migrated onto the first page.
+func findStarwarsDataHash(ctx context.Context, source, pageUrl string, firstPage bool) (*StarwarsDataHash, error) {
+	savedData := &StarwarsDataHash{}
+
+	err := mgm.Coll(savedData).FirstWithCtx(ctx, bson.M{"source": source, "page_url": pageUrl}, savedData)
+	if err == nil {
+		return savedData, nil
+	}
+	if err != mongo.ErrNoDocuments {
+		return nil, err
 	}
 
-	// update db with new data
-	if !noNewData {
-		for _, starwarsFilm := range data.Results {
-			var film []Film
-			if err = mgm.Coll(&Film{}).SimpleFind(&film, bson.M{"title": starwarsFilm.Title}); err != nil {
-				logger.Error("Error:", zap.Error(err))
-				continue
-			}
-			if len(film) <= 0 {
-				err = mgm.Coll(&Film{}).Create(&Film{
-					Title:       starwarsFilm.Title,
-					ReleaseDate: starwarsFilm.ReleaseDate,
-				})
-				if err != nil {
-					logger.Error("Error:", zap.Error(err))
-					continue
-				}
-			}
+	savedData.Source = source
+	savedData.PageUrl = pageUrl
+
+	if firstPage {
+		var legacyData StarwarsDataHash
+		err = mgm.Coll(savedData).FirstWithCtx(ctx, bson.M{"source": bson.M{"$exists": false}}, &legacyData)
+		if err == mongo.ErrNoDocuments {
+			return savedData, nil
+		}
+		if err != nil {
+			return nil, err
 		}
-	}
 
-	// go to next page
-	if data.Next != "" {
-		if err = UpdateFilmFromSource(logger, data.Next); err != nil {
-			return err
+		_, err = mgm.Coll(savedData).UpdateByID(ctx, legacyData.ID, bson.M{"$set": bson.M{
+			"source":   source,
+			"page_url": pageUrl,
+		}})
+		if err != nil {
+			return nil, err
 		}
+
+		legacyData.Source = source
+		legacyData.PageUrl = pageUrl
+		return &legacyData, nil
 	}
 
-	return nil
+	return savedData, nil
 }
 
-func compareStarwarsDataHash(savedHashObj *StarwarsDataHash, newDataHash string) (bool, error) {
-	if savedHashObj.Hash != newDataHash {
-		//save new hash
-		savedHashObj.Hash = newDataHash
-		err := mgm.Coll(&StarwarsDataHash{}).UpdateWithCtx(context.Background(), savedHashObj)
-		if err != nil {
-			return false, err
-		}
+// saveStarwarsDataHash saves the new hash of a page once its data is saved.
+func saveStarwarsDataHash(ctx context.Context, savedHashObj *StarwarsDataHash, newDataHash string) error {
+	savedHashObj.Hash = newDataHash
 
-		return false, nil
+	if savedHashObj.ID.IsZero() {
+		return mgm.Coll(savedHashObj).CreateWithCtx(ctx, savedHashObj)
 	}
-
-	return true, nil
+	return mgm.Coll(savedHashObj).UpdateWithCtx(ctx, savedHashObj)
 }
 
-func hashStarwarsApiData(data []Film) (string, error) {
+func hashStarwarsApiData(data interface{}) (string, error) {
 	var hash string
 	dataJSON, err := json.Marshal(data)
 	if err != nil {
@@ -145,9 +437,11 @@ func hashStarwarsApiData(data []Film) (string, error) {
 
 type FilmRepository interface {
 	GetById(ctx context.Context, id string) (*Film, error)
-	FetchPaginatedFilms(ctx context.Context, page, limit int64) (*PaginatedFilm, error)
+	SetAllowAnonymousComments(ctx context.Context, id string, allow bool) (*Film, error)
+	FetchPaginatedFilms(ctx context.Context, query FilmQuery, page, limit int64) (*PaginatedFilm, error)
+	FetchCursorFilms(ctx context.Context, query FilmQuery, cursor *Cursor, limit int64) (*PaginatedFilm, error)
 }
 
 type FilmUsecase interface {
-	FetchFilmsFromAllSources(ctx context.Context, page, limit int64) (*PaginatedFilm, error)
+	SyncFilmsFromAllSources(ctx context.Context) []SyncRun
 }
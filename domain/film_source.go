@@ -0,0 +1,70 @@
+package domain
+
+import (
+	"context"
+	"sync"
+)
+
+// FilmSource is an external catalog the films collection is synced from.
+// Films returned by a source carry its Name as Source and a stable ExternalId.
+type FilmSource interface {
+	// Name identifies the source, e.g. "swapi".
+	Name() string
+	// FetchPage returns a single page of films. An empty pageUrl means the first page.
+	FetchPage(ctx context.Context, pageUrl string) (*FilmSourcePage, error)
+}
+
+type FilmSourcePage struct {
+	Url     string `json:"url" bson:"url"`
+	Next    string `json:"next" bson:"next"`
+	Count   int64  `json:"count" bson:"count"`
+	Results []Film `json:"results" bson:"results"`
+}
+
+// FilmSourceRegistry holds every FilmSource the films are synced from.
+type FilmSourceRegistry struct {
+	mu      sync.RWMutex
+	sources []FilmSource
+}
+
+func NewFilmSourceRegistry(sources ...FilmSource) *FilmSourceRegistry {
+	r := &FilmSourceRegistry{}
+	for _, source := range sources {
+		r.Register(source)
+	}
+	return r
+}
+
+func (r *FilmSourceRegistry) Register(source FilmSource) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i, s := range r.sources {
+		if s.Name() == source.Name() {
+			r.sources[i] = source
+			return
+		}
+	}
+	r.sources = append(r.sources, source)
+}
+
+func (r *FilmSourceRegistry) Get(name string) (FilmSource, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, s := range r.sources {
+		if s.Name() == name {
+			return s, true
+		}
+	}
+	return nil, false
+}
+
+func (r *FilmSourceRegistry) Sources() []FilmSource {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	sources := make([]FilmSource, len(r.sources))
+	copy(sources, r.sources)
+	return sources
+}
@@ -0,0 +1,36 @@
+package domain
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseFilmFields(t *testing.T) {
+	tests := []struct {
+		name    string
+		raw     string
+		want    []string
+		wantErr bool
+	}{
+		{name: "empty", raw: "", want: nil},
+		{name: "blank", raw: "  ", want: nil},
+		{name: "single", raw: "title", want: []string{"_id", "title"}},
+		{name: "trimmed", raw: " title , director ", want: []string{"_id", "title", "director"}},
+		{name: "duplicates", raw: "title,created_at,title,created_at", want: []string{"_id", "title", "created_at"}},
+		{name: "id", raw: "_id,title", want: []string{"_id", "title"}},
+		{name: "empty entries", raw: "title,,director,", want: []string{"_id", "title", "director"}},
+		{name: "unknown", raw: "title,password", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseFilmFields(tt.raw)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
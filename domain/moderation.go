@@ -0,0 +1,100 @@
+package domain
+
+import (
+	"context"
+	"github.com/Kamva/mgm/v2"
+	mongopagination "github.com/gobeam/mongo-go-pagination"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"time"
+)
+
+const (
+	ReportReasonSpam     = "spam"
+	ReportReasonAbuse    = "abuse"
+	ReportReasonSpoiler  = "spoiler"
+	ReportReasonOffTopic = "off_topic"
+	ReportReasonOther    = "other"
+)
+
+const (
+	ModerationApprove  = "approve"
+	ModerationReject   = "reject"
+	ModerationDismiss  = "dismiss"
+	ModerationHide     = "hide"
+	ModerationDelete   = "delete"
+	ModerationBan      = "ban"
+	ModerationAutoHide = "auto_hide"
+)
+
+// CommentReport is a user flagging a comment, one per user per comment.
+type CommentReport struct {
+	mgm.DefaultModel `bson:",inline"`
+	CommentId        string     `json:"comment_id" bson:"comment_id"`
+	UserId           string     `json:"user_id" bson:"user_id"`
+	Reason           string     `json:"reason" bson:"reason"`
+	Note             string     `json:"note,omitempty" bson:"note,omitempty"`
+	DismissedAt      *time.Time `json:"dismissed_at,omitempty" bson:"dismissed_at"`
+}
+
+type ReportCommentRequest struct {
+	Reason string `validate:"required,oneof=spam abuse spoiler off_topic other" json:"reason"`
+	Note   string `validate:"max=500" json:"note"`
+}
+
+// ModerationAction records what a moderator, or the report threshold when
+// ModeratorId is empty, did to a comment.
+type ModerationAction struct {
+	mgm.DefaultModel `bson:",inline"`
+	CommentId        string `json:"comment_id" bson:"comment_id"`
+	ModeratorId      string `json:"moderator_id,omitempty" bson:"moderator_id,omitempty"`
+	AuthorId         string `json:"author_id,omitempty" bson:"author_id,omitempty"`
+	Action           string `json:"action" bson:"action"`
+	Note             string `json:"note,omitempty" bson:"note,omitempty"`
+}
+
+type PaginatedModerationAction struct {
+	Pagination *mongopagination.PaginatedData `json:"pagination" bson:"pagination"`
+	Data       []ModerationAction             `json:"data" bson:"data"`
+}
+
+type ModerateCommentRequest struct {
+	Action string `validate:"required,oneof=dismiss hide delete ban" json:"action"`
+	Note   string `validate:"max=500" json:"note"`
+}
+
+type CommentReportRepository interface {
+	Create(ctx context.Context, report *CommentReport) (*CommentReport, error)
+	// DismissAll dismisses the open reports of the comment.
+	DismissAll(ctx context.Context, commentId string) error
+}
+
+type ModerationActionRepository interface {
+	Create(ctx context.Context, action *ModerationAction) (*ModerationAction, error)
+	FetchPaginatedCommentActions(ctx context.Context, commentId string, page, limit int64) (*PaginatedModerationAction, error)
+}
+
+type ModerationUsecase interface {
+	ReportComment(ctx context.Context, id, userId string, reqBody *ReportCommentRequest) (*CommentReport, error)
+	FetchReportedComments(ctx context.Context, page, limit int64) (*PaginatedComment, error)
+	FetchHeldComments(ctx context.Context, page, limit int64) (*PaginatedComment, error)
+	ApproveComment(ctx context.Context, id, userId string) (*Comment, error)
+	RejectComment(ctx context.Context, id, userId string) error
+	ModerateComment(ctx context.Context, id, userId string, reqBody *ModerateCommentRequest) (*Comment, error)
+	FetchModerationActions(ctx context.Context, id string, page, limit int64) (*PaginatedModerationAction, error)
+}
+
+// After Create Hook. Inherited from mgm.CreateWithCtx
+func (m CommentReport) Created() error {
+	commentId, err := primitive.ObjectIDFromHex(m.CommentId)
+	if err != nil {
+		return err
+	}
+
+	_, err = mgm.Coll(&Comment{}).UpdateOne(
+		context.Background(),
+		bson.M{"_id": commentId},
+		bson.M{"$inc": bson.M{"report_count": 1}})
+
+	return err
+}
@@ -0,0 +1,39 @@
+package domain
+
+import (
+	"context"
+
+	"github.com/Kamva/mgm/v2"
+	mongopagination "github.com/gobeam/mongo-go-pagination"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type Planet struct {
+	mgm.DefaultModel `bson:",inline"`
+	SourceResource   `bson:",inline"`
+	Name             string               `json:"name" bson:"name"`
+	RotationPeriod   string               `json:"rotation_period" bson:"rotation_period"`
+	OrbitalPeriod    string               `json:"orbital_period" bson:"orbital_period"`
+	Diameter         string               `json:"diameter" bson:"diameter"`
+	Climate          string               `json:"climate" bson:"climate"`
+	Gravity          string               `json:"gravity" bson:"gravity"`
+	Terrain          string               `json:"terrain" bson:"terrain"`
+	SurfaceWater     string               `json:"surface_water" bson:"surface_water"`
+	Population       string               `json:"population" bson:"population"`
+	Residents        []string             `json:"residents" bson:"residents"`
+	ResidentIds      []primitive.ObjectID `json:"resident_ids,omitempty" bson:"resident_ids,omitempty"`
+}
+
+func (m *Planet) CollectionName() string {
+	return string(ResourcePlanets)
+}
+
+type PaginatedPlanet struct {
+	Pagination *mongopagination.PaginatedData `json:"pagination" bson:"pagination"`
+	Data       []Planet                       `json:"data" bson:"data"`
+}
+
+type PlanetRepository interface {
+	GetById(ctx context.Context, id string) (*Planet, error)
+	FetchPaginatedPlanets(ctx context.Context, page, limit int64) (*PaginatedPlanet, error)
+}
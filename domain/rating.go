@@ -0,0 +1,32 @@
+package domain
+
+import (
+	"context"
+	"github.com/Kamva/mgm/v2"
+)
+
+// Rating is the 1-10 score a user gives a film, one per user per film.
+type Rating struct {
+	mgm.DefaultModel `bson:",inline"`
+	FilmId           string `json:"film_id" bson:"film_id"`
+	UserId           string `json:"user_id" bson:"user_id"`
+	Score            int64  `json:"score" bson:"score"`
+}
+
+type RatingRequest struct {
+	Score int64 `validate:"required,min=1,max=10" json:"score"`
+}
+
+type RatingRepository interface {
+	// Upsert creates or replaces the rating of the user for the film and
+	// updates the film rating_count and rating_avg in the same transaction.
+	Upsert(ctx context.Context, rating *Rating) (*Rating, error)
+	Delete(ctx context.Context, filmId, userId string) error
+	GetByFilmAndUser(ctx context.Context, filmId, userId string) (*Rating, error)
+}
+
+type RatingUsecase interface {
+	RateFilm(ctx context.Context, filmId, userId string, request *RatingRequest) (*Rating, error)
+	DeleteRating(ctx context.Context, filmId, userId string) error
+	FetchUserRating(ctx context.Context, filmId, userId string) (*Rating, error)
+}
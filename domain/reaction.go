@@ -0,0 +1,101 @@
+package domain
+
+import (
+	"context"
+	"github.com/Kamva/mgm/v2"
+	mongopagination "github.com/gobeam/mongo-go-pagination"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+const (
+	ReactionLike      = "like"
+	ReactionDislike   = "dislike"
+	ReactionHeart     = "heart"
+	ReactionLaugh     = "laugh"
+	ReactionSurprised = "surprised"
+	ReactionSad       = "sad"
+	ReactionAngry     = "angry"
+)
+
+// ReactionTypes are the reactions a user can leave on a comment, one of each.
+var ReactionTypes = []string{
+	ReactionLike,
+	ReactionDislike,
+	ReactionHeart,
+	ReactionLaugh,
+	ReactionSurprised,
+	ReactionSad,
+	ReactionAngry,
+}
+
+func IsReactionType(reactionType string) bool {
+	for _, t := range ReactionTypes {
+		if t == reactionType {
+			return true
+		}
+	}
+	return false
+}
+
+// reactionScore is how much a reaction adds to the reaction_score comments
+// are ordered by with sort=top. Emoji reactions are neutral.
+func reactionScore(reactionType string) int64 {
+	switch reactionType {
+	case ReactionLike:
+		return 1
+	case ReactionDislike:
+		return -1
+	}
+	return 0
+}
+
+type Reaction struct {
+	mgm.DefaultModel `bson:",inline"`
+	CommentId        string `json:"comment_id" bson:"comment_id"`
+	UserId           string `json:"user_id" bson:"user_id"`
+	Type             string `json:"type" bson:"type"`
+}
+
+type PaginatedReaction struct {
+	Pagination *mongopagination.PaginatedData `json:"pagination" bson:"pagination"`
+	Data       []Reaction                     `json:"data" bson:"data"`
+}
+
+type ReactionRepository interface {
+	Create(ctx context.Context, reaction *Reaction) (*Reaction, error)
+	Delete(ctx context.Context, commentId, userId, reactionType string) error
+	FetchPaginatedCommentReactions(ctx context.Context, commentId, reactionType string, page, limit int64) (*PaginatedReaction, error)
+}
+
+// After Create Hook. Inherited from mgm.CreateWithCtx
+func (m Reaction) Created() error {
+	return m.incCommentReactions(1)
+}
+
+// After Delete Hook. Inherited from mgm.DeleteWithCtx
+func (m Reaction) Deleted(result *mongo.DeleteResult) error {
+	if result.DeletedCount == 0 {
+		return nil
+	}
+	return m.incCommentReactions(-1)
+}
+
+// incCommentReactions keeps the reaction counts of the comment in sync.
+func (m Reaction) incCommentReactions(n int64) error {
+	commentId, err := primitive.ObjectIDFromHex(m.CommentId)
+	if err != nil {
+		return err
+	}
+
+	_, err = mgm.Coll(&Comment{}).UpdateOne(
+		context.Background(),
+		bson.M{"_id": commentId},
+		bson.M{"$inc": bson.M{
+			"reaction_counts." + m.Type: n,
+			"reaction_score":            n * reactionScore(m.Type),
+		}})
+
+	return err
+}
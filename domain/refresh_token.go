@@ -0,0 +1,100 @@
+package domain
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"github.com/Kamva/mgm/v2"
+	"os"
+	"time"
+)
+
+var (
+	ErrInvalidRefreshToken = errors.New("invalid or expired refresh token")
+	ErrRefreshTokenReused  = errors.New("refresh token reused, please login again")
+)
+
+const (
+	defaultAccessTokenTTL  = 15 * time.Minute
+	defaultRefreshTokenTTL = 30 * 24 * time.Hour
+)
+
+// RefreshToken is a long-lived token exchanged for a new access token. Only
+// its hash is stored. Every refresh rotates it, and the tokens descending
+// from the same login share a FamilyId.
+type RefreshToken struct {
+	mgm.DefaultModel `bson:",inline"`
+	UserId           string     `json:"user_id" bson:"user_id"`
+	FamilyId         string     `json:"family_id" bson:"family_id"`
+	TokenHash        string     `json:"-" bson:"token_hash"`
+	ExpiresAt        time.Time  `json:"expires_at" bson:"expires_at"`
+	UsedAt           *time.Time `json:"used_at,omitempty" bson:"used_at"`
+	RevokedAt        *time.Time `json:"revoked_at,omitempty" bson:"revoked_at"`
+}
+
+type TokenPair struct {
+	AccessToken  string `json:"token"`
+	RefreshToken string `json:"refresh_token"`
+	ExpiresIn    int64  `json:"expires_in"`
+}
+
+type RefreshRequest struct {
+	RefreshToken string `validate:"required" json:"refresh_token"`
+}
+
+type RefreshTokenRepository interface {
+	Create(ctx context.Context, token *RefreshToken) (*RefreshToken, error)
+	GetByHash(ctx context.Context, tokenHash string) (*RefreshToken, error)
+	// MarkUsed marks the token as used and reports false when it already was.
+	MarkUsed(ctx context.Context, token *RefreshToken) (bool, error)
+	RevokeFamily(ctx context.Context, familyId string) error
+	RevokeUser(ctx context.Context, userId string) error
+}
+
+// NewRefreshToken returns a random refresh token and its hash.
+func NewRefreshToken() (string, string, error) {
+	return newSecretToken()
+}
+
+func HashRefreshToken(token string) string {
+	return hashSecretToken(token)
+}
+
+// newSecretToken returns a random token and the hash it is stored as.
+func newSecretToken() (string, string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", "", err
+	}
+
+	token := base64.RawURLEncoding.EncodeToString(b)
+	return token, hashSecretToken(token), nil
+}
+
+func hashSecretToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// AccessTokenTTL is how long access tokens are valid, ACCESS_TOKEN_TTL or
+// 15 minutes by default.
+func AccessTokenTTL() time.Duration {
+	return durationEnv("ACCESS_TOKEN_TTL", defaultAccessTokenTTL)
+}
+
+// RefreshTokenTTL is how long refresh tokens are valid, REFRESH_TOKEN_TTL
+// or 30 days by default.
+func RefreshTokenTTL() time.Duration {
+	return durationEnv("REFRESH_TOKEN_TTL", defaultRefreshTokenTTL)
+}
+
+func durationEnv(key string, fallback time.Duration) time.Duration {
+	d, err := time.ParseDuration(os.Getenv(key))
+	if err != nil || d <= 0 {
+		return fallback
+	}
+	return d
+}
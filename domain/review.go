@@ -0,0 +1,134 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"github.com/Kamva/mgm/v2"
+	mongopagination "github.com/gobeam/mongo-go-pagination"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+var (
+	ErrReviewExists     = errors.New("film already reviewed by this user")
+	ErrReviewForbidden  = errors.New("review belongs to another user")
+	ErrOwnReviewHelpful = errors.New("cannot mark your own review as helpful")
+)
+
+const (
+	ReviewSortNewest  = "newest"
+	ReviewSortHelpful = "helpful"
+)
+
+// Review is a long-form review of a film, one per user per film. Its rating
+// stands on its own and does not count towards the film rating_avg.
+type Review struct {
+	mgm.DefaultModel `bson:",inline"`
+	FilmId           string `json:"film_id" bson:"film_id"`
+	UserId           string `json:"user_id" bson:"user_id"`
+	Title            string `json:"title" bson:"title"`
+	Body             string `json:"body" bson:"body"`
+	Rating           int64  `json:"rating" bson:"rating"`
+	Spoiler          bool   `json:"spoiler" bson:"spoiler"`
+	HelpfulCount     int64  `json:"helpful_count" bson:"helpful_count"`
+}
+
+type PaginatedReview struct {
+	Pagination *mongopagination.PaginatedData `json:"pagination" bson:"pagination"`
+	Data       []Review                       `json:"data" bson:"data"`
+}
+
+type ReviewRequest struct {
+	Title   string `validate:"required,max=200" json:"title"`
+	Body    string `validate:"required,max=10000" json:"body"`
+	Rating  int64  `validate:"required,min=1,max=10" json:"rating"`
+	Spoiler bool   `json:"spoiler"`
+}
+
+// ReviewVote marks a review as helpful for the user who cast it.
+type ReviewVote struct {
+	mgm.DefaultModel `bson:",inline"`
+	ReviewId         string `json:"review_id" bson:"review_id"`
+	UserId           string `json:"user_id" bson:"user_id"`
+}
+
+type ReviewRepository interface {
+	Create(ctx context.Context, review *Review) (*Review, error)
+	GetById(ctx context.Context, id string) (*Review, error)
+	Update(ctx context.Context, review *Review) (*Review, error)
+	Delete(ctx context.Context, review *Review) error
+	FetchPaginatedFilmReviews(ctx context.Context, filmId, sort string, page, limit int64) (*PaginatedReview, error)
+}
+
+type ReviewVoteRepository interface {
+	Create(ctx context.Context, vote *ReviewVote) (*ReviewVote, error)
+	Delete(ctx context.Context, reviewId, userId string) error
+	DeleteByReview(ctx context.Context, reviewId string) error
+}
+
+type ReviewUsecase interface {
+	AddReview(ctx context.Context, filmId, userId string, reqBody *ReviewRequest) (*Review, error)
+	UpdateReview(ctx context.Context, filmId, id, userId string, reqBody *ReviewRequest) (*Review, error)
+	DeleteReview(ctx context.Context, filmId, id, userId, role string) error
+	FetchReview(ctx context.Context, filmId, id string) (*Review, error)
+	FetchFilmReviews(ctx context.Context, filmId, sort string, page, limit int64) (*PaginatedReview, error)
+	MarkHelpful(ctx context.Context, filmId, id, userId string) (*ReviewVote, error)
+	UnmarkHelpful(ctx context.Context, filmId, id, userId string) error
+}
+
+// After Create Hook. Inherited from mgm.CreateWithCtx
+func (m Review) Created() error {
+	return m.incFilmReviews(1)
+}
+
+// After Delete Hook. Inherited from mgm.DeleteWithCtx
+func (m Review) Deleted(result *mongo.DeleteResult) error {
+	if result.DeletedCount == 0 {
+		return nil
+	}
+	return m.incFilmReviews(-1)
+}
+
+// incFilmReviews keeps the review_count of the film in sync.
+func (m Review) incFilmReviews(n int64) error {
+	filmId, err := primitive.ObjectIDFromHex(m.FilmId)
+	if err != nil {
+		return err
+	}
+
+	_, err = mgm.Coll(&Film{}).UpdateOne(
+		context.Background(),
+		bson.M{"_id": filmId},
+		bson.M{"$inc": bson.M{"review_count": n}})
+
+	return err
+}
+
+// After Create Hook. Inherited from mgm.CreateWithCtx
+func (m ReviewVote) Created() error {
+	return m.incReviewHelpful(1)
+}
+
+// After Delete Hook. Inherited from mgm.DeleteWithCtx
+func (m ReviewVote) Deleted(result *mongo.DeleteResult) error {
+	if result.DeletedCount == 0 {
+		return nil
+	}
+	return m.incReviewHelpful(-1)
+}
+
+// incReviewHelpful keeps the helpful_count of the review in sync.
+func (m ReviewVote) incReviewHelpful(n int64) error {
+	reviewId, err := primitive.ObjectIDFromHex(m.ReviewId)
+	if err != nil {
+		return err
+	}
+
+	_, err = mgm.Coll(&Review{}).UpdateOne(
+		context.Background(),
+		bson.M{"_id": reviewId},
+		bson.M{"$inc": bson.M{"helpful_count": n}})
+
+	return err
+}
@@ -0,0 +1,42 @@
+package domain
+
+import (
+	"context"
+	"github.com/Kamva/mgm/v2"
+	"time"
+)
+
+// RevocationStore keeps the access tokens revoked before they expire.
+type RevocationStore interface {
+	// RevokeToken revokes the token with the jti until it expires.
+	RevokeToken(ctx context.Context, jti string, expiresAt time.Time) error
+	// RevokeUser revokes every token of the user issued up to now.
+	RevokeUser(ctx context.Context, userId string) error
+	IsRevoked(ctx context.Context, jti, userId string, issuedAt time.Time) (bool, error)
+}
+
+// RevokedToken is a revoked access token, kept until it expires.
+type RevokedToken struct {
+	mgm.DefaultModel `bson:",inline"`
+	Jti              string    `json:"jti" bson:"jti"`
+	ExpiresAt        time.Time `json:"expires_at" bson:"expires_at"`
+}
+
+// RevokedUser invalidates the tokens of a user issued up to RevokedAt.
+type RevokedUser struct {
+	mgm.DefaultModel `bson:",inline"`
+	UserId           string    `json:"user_id" bson:"user_id"`
+	RevokedAt        time.Time `json:"revoked_at" bson:"revoked_at"`
+}
+
+type LogoutRequest struct {
+	// RefreshToken, when given, is revoked with its family.
+	RefreshToken string `json:"refresh_token"`
+}
+
+// IssuedUpTo reports whether a token issued at issuedAt was issued up to the
+// revocation time. Both are compared in milliseconds, the precision of the
+// stored revocation time, so tokens issued after it stay valid.
+func IssuedUpTo(issuedAt, revokedAt time.Time) bool {
+	return issuedAt.UnixMilli() <= revokedAt.UnixMilli()
+}
@@ -0,0 +1,50 @@
+package domain
+
+import (
+	"testing"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+)
+
+func TestIssuedUpTo(t *testing.T) {
+	revokedAt := time.Date(2023, 5, 1, 12, 0, 0, 500*int(time.Millisecond), time.UTC)
+
+	tests := []struct {
+		name     string
+		issuedAt time.Time
+		want     bool
+	}{
+		{name: "earlier second", issuedAt: revokedAt.Add(-time.Second), want: true},
+		{name: "earlier in the same second", issuedAt: revokedAt.Add(-100 * time.Millisecond), want: true},
+		{name: "same millisecond", issuedAt: revokedAt.Add(300 * time.Microsecond), want: true},
+		{name: "later in the same second", issuedAt: revokedAt.Add(100 * time.Millisecond), want: false},
+		{name: "later second", issuedAt: revokedAt.Add(time.Second), want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IssuedUpTo(tt.issuedAt, revokedAt); got != tt.want {
+				t.Errorf("IssuedUpTo(%v, %v) = %v, want %v", tt.issuedAt, revokedAt, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAuthClaimsIssuedTime(t *testing.T) {
+	issuedAt := time.Date(2023, 5, 1, 12, 0, 0, 250*int(time.Millisecond), time.UTC)
+
+	claims := AuthClaims{StandardClaims: jwt.StandardClaims{IssuedAt: issuedAt.Unix()}, IssuedAtMs: issuedAt.UnixMilli()}
+	if got := claims.IssuedTime(); !got.Equal(issuedAt) {
+		t.Errorf("with iat_ms = %v, want %v", got, issuedAt)
+	}
+
+	// tokens without iat_ms are revoked by a revocation in their second
+	claims.IssuedAtMs = 0
+	if !IssuedUpTo(claims.IssuedTime(), issuedAt.Truncate(time.Second)) {
+		t.Error("token without iat_ms not covered by a revocation in its second")
+	}
+	if IssuedUpTo(claims.IssuedTime(), issuedAt.Truncate(time.Second).Add(-time.Millisecond)) {
+		t.Error("token without iat_ms covered by a revocation before its second")
+	}
+}
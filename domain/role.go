@@ -0,0 +1,37 @@
+package domain
+
+const (
+	RoleUser      = "user"
+	RoleModerator = "moderator"
+	RoleAdmin     = "admin"
+)
+
+// roleRanks orders the roles, each role holding the rights of the ones below.
+var roleRanks = map[string]int{
+	RoleUser:      1,
+	RoleModerator: 2,
+	RoleAdmin:     3,
+}
+
+func IsRole(role string) bool {
+	_, ok := roleRanks[role]
+	return ok
+}
+
+// HasRole reports whether role holds the rights of required. Users saved
+// before roles were added have no role and are plain users. Unknown roles
+// hold no rights and are never held.
+func HasRole(role, required string) bool {
+	if role == "" {
+		role = RoleUser
+	}
+	if !IsRole(role) || !IsRole(required) {
+		return false
+	}
+	return roleRanks[role] >= roleRanks[required]
+}
+
+// IsModerator reports whether role holds the moderator rights.
+func IsModerator(role string) bool {
+	return HasRole(role, RoleModerator)
+}
@@ -0,0 +1,32 @@
+package domain
+
+import "testing"
+
+func TestHasRole(t *testing.T) {
+	tests := []struct {
+		role     string
+		required string
+		want     bool
+	}{
+		{role: RoleUser, required: RoleUser, want: true},
+		{role: "", required: RoleUser, want: true},
+		{role: "", required: RoleModerator},
+		{role: RoleUser, required: RoleModerator},
+		{role: RoleModerator, required: RoleModerator, want: true},
+		{role: RoleModerator, required: RoleAdmin},
+		{role: RoleAdmin, required: RoleModerator, want: true},
+		{role: RoleAdmin, required: RoleAdmin, want: true},
+		{role: RoleAdmin, required: "admn"},
+		{role: RoleUser, required: "admn"},
+		{role: RoleUser, required: ""},
+		{role: "superuser", required: RoleUser},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.role+"/"+tt.required, func(t *testing.T) {
+			if got := HasRole(tt.role, tt.required); got != tt.want {
+				t.Errorf("HasRole(%q, %q) = %v, want %v", tt.role, tt.required, got, tt.want)
+			}
+		})
+	}
+}
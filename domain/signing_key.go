@@ -0,0 +1,222 @@
+package domain
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"strings"
+
+	"github.com/dgrijalva/jwt-go"
+)
+
+// SigningKey is a key signing or verifying access tokens.
+type SigningKey struct {
+	Kid    string
+	Method jwt.SigningMethod
+	// Key signs tokens: the private key, or the secret for HS256.
+	Key interface{}
+	// PublicKey verifies tokens. Nil for HS256.
+	PublicKey interface{}
+}
+
+// KeySet holds the key signing new tokens and the retired keys still
+// verifying the tokens they signed.
+type KeySet struct {
+	Active  *SigningKey
+	Retired []*SigningKey
+}
+
+// JWK is a public key in the JSON Web Key format.
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+	Y   string `json:"y,omitempty"`
+}
+
+// JWKS is the key set served at /.well-known/jwks.json.
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+// LoadKeySet reads the signing keys from the config:
+//   - JWT_SIGNING_KEY_FILE, the PEM private key signing new tokens, RSA for
+//     RS256 or P-256 EC for ES256
+//   - JWT_RETIRED_KEY_FILES, comma separated PEM public or private keys
+//     still accepted for verification
+//
+// Tokens are signed with JWT_SECRET_KEY and HS256 when no key file is set.
+func LoadKeySet() (*KeySet, error) {
+	path := os.Getenv("JWT_SIGNING_KEY_FILE")
+	if path == "" {
+		secret := os.Getenv("JWT_SECRET_KEY")
+		if secret == "" {
+			return nil, errors.New("JWT_SIGNING_KEY_FILE or JWT_SECRET_KEY is required")
+		}
+		return &KeySet{Active: &SigningKey{Method: jwt.SigningMethodHS256, Key: []byte(secret)}}, nil
+	}
+
+	active, err := loadSigningKey(path, true)
+	if err != nil {
+		return nil, err
+	}
+
+	keys := &KeySet{Active: active}
+
+	for _, path := range strings.Split(os.Getenv("JWT_RETIRED_KEY_FILES"), ",") {
+		if path = strings.TrimSpace(path); path == "" {
+			continue
+		}
+
+		key, err := loadSigningKey(path, false)
+		if err != nil {
+			return nil, err
+		}
+		keys.Retired = append(keys.Retired, key)
+	}
+
+	return keys, nil
+}
+
+// loadSigningKey reads a PEM key file. Retired keys may be public keys.
+func loadSigningKey(path string, private bool) (*SigningKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	key := &SigningKey{}
+
+	if rsaKey, err := jwt.ParseRSAPrivateKeyFromPEM(data); err == nil {
+		key.Key, key.PublicKey = rsaKey, &rsaKey.PublicKey
+	} else if ecKey, err := jwt.ParseECPrivateKeyFromPEM(data); err == nil {
+		key.Key, key.PublicKey = ecKey, &ecKey.PublicKey
+	} else if private {
+		return nil, fmt.Errorf("%s: not an RSA or EC private key", path)
+	} else if rsaKey, err := jwt.ParseRSAPublicKeyFromPEM(data); err == nil {
+		key.PublicKey = rsaKey
+	} else if ecKey, err := jwt.ParseECPublicKeyFromPEM(data); err == nil {
+		key.PublicKey = ecKey
+	} else {
+		return nil, fmt.Errorf("%s: not an RSA or EC key", path)
+	}
+
+	switch pub := key.PublicKey.(type) {
+	case *rsa.PublicKey:
+		key.Method = jwt.SigningMethodRS256
+	case *ecdsa.PublicKey:
+		if pub.Curve != elliptic.P256() {
+			return nil, fmt.Errorf("%s: only P-256 EC keys are supported", path)
+		}
+		key.Method = jwt.SigningMethodES256
+	}
+
+	kid, err := key.thumbprint()
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	key.Kid = kid
+
+	return key, nil
+}
+
+// Sign signs the claims with the active key, naming it in the kid header.
+func (k *KeySet) Sign(claims jwt.Claims) (string, error) {
+	token := jwt.NewWithClaims(k.Active.Method, claims)
+	if k.Active.Kid != "" {
+		token.Header["kid"] = k.Active.Kid
+	}
+
+	return token.SignedString(k.Active.Key)
+}
+
+// VerificationKeys returns the public keys by kid, the active key first.
+// It is empty when tokens are signed with a secret.
+func (k *KeySet) VerificationKeys() map[string]interface{} {
+	keys := map[string]interface{}{}
+	for _, key := range append([]*SigningKey{k.Active}, k.Retired...) {
+		if key.PublicKey != nil {
+			keys[key.Kid] = key.PublicKey
+		}
+	}
+	return keys
+}
+
+// JWKS returns the public keys verifying tokens.
+func (k *KeySet) JWKS() JWKS {
+	jwks := JWKS{Keys: []JWK{}}
+	for _, key := range append([]*SigningKey{k.Active}, k.Retired...) {
+		if jwk, ok := key.JWK(); ok {
+			jwks.Keys = append(jwks.Keys, jwk)
+		}
+	}
+	return jwks
+}
+
+// JWK returns the public key as a JWK, false for secrets.
+func (k *SigningKey) JWK() (JWK, bool) {
+	jwk := JWK{Kid: k.Kid, Use: "sig", Alg: k.Method.Alg()}
+
+	switch pub := k.PublicKey.(type) {
+	case *rsa.PublicKey:
+		jwk.Kty = "RSA"
+		jwk.N = base64.RawURLEncoding.EncodeToString(pub.N.Bytes())
+		jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes())
+	case *ecdsa.PublicKey:
+		size := (pub.Curve.Params().BitSize + 7) / 8
+		jwk.Kty = "EC"
+		jwk.Crv = pub.Curve.Params().Name
+		jwk.X = base64.RawURLEncoding.EncodeToString(pub.X.FillBytes(make([]byte, size)))
+		jwk.Y = base64.RawURLEncoding.EncodeToString(pub.Y.FillBytes(make([]byte, size)))
+	default:
+		return JWK{}, false
+	}
+
+	return jwk, true
+}
+
+// thumbprint is the RFC 7638 thumbprint of the public key, used as its kid
+// so a key keeps its kid once retired.
+func (k *SigningKey) thumbprint() (string, error) {
+	jwk, ok := k.JWK()
+	if !ok {
+		return "", errors.New("unsupported key")
+	}
+
+	// the required members in lexicographic order
+	var members interface{}
+	if jwk.Kty == "RSA" {
+		members = struct {
+			E   string `json:"e"`
+			Kty string `json:"kty"`
+			N   string `json:"n"`
+		}{jwk.E, jwk.Kty, jwk.N}
+	} else {
+		members = struct {
+			Crv string `json:"crv"`
+			Kty string `json:"kty"`
+			X   string `json:"x"`
+			Y   string `json:"y"`
+		}{jwk.Crv, jwk.Kty, jwk.X, jwk.Y}
+	}
+
+	data, err := json.Marshal(members)
+	if err != nil {
+		return "", err
+	}
+
+	sum := sha256.Sum256(data)
+	return base64.RawURLEncoding.EncodeToString(sum[:]), nil
+}
@@ -0,0 +1,41 @@
+package domain
+
+import (
+	"context"
+
+	"github.com/Kamva/mgm/v2"
+	mongopagination "github.com/gobeam/mongo-go-pagination"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type Species struct {
+	mgm.DefaultModel `bson:",inline"`
+	SourceResource   `bson:",inline"`
+	Name             string               `json:"name" bson:"name"`
+	Classification   string               `json:"classification" bson:"classification"`
+	Designation      string               `json:"designation" bson:"designation"`
+	AverageHeight    string               `json:"average_height" bson:"average_height"`
+	SkinColors       string               `json:"skin_colors" bson:"skin_colors"`
+	HairColors       string               `json:"hair_colors" bson:"hair_colors"`
+	EyeColors        string               `json:"eye_colors" bson:"eye_colors"`
+	AverageLifespan  string               `json:"average_lifespan" bson:"average_lifespan"`
+	Language         string               `json:"language" bson:"language"`
+	Homeworld        *string              `json:"homeworld" bson:"homeworld"`
+	HomeworldId      *primitive.ObjectID  `json:"homeworld_id,omitempty" bson:"homeworld_id,omitempty"`
+	People           []string             `json:"people" bson:"people"`
+	PeopleIds        []primitive.ObjectID `json:"people_ids,omitempty" bson:"people_ids,omitempty"`
+}
+
+func (m *Species) CollectionName() string {
+	return string(ResourceSpecies)
+}
+
+type PaginatedSpecies struct {
+	Pagination *mongopagination.PaginatedData `json:"pagination" bson:"pagination"`
+	Data       []Species                      `json:"data" bson:"data"`
+}
+
+type SpeciesRepository interface {
+	GetById(ctx context.Context, id string) (*Species, error)
+	FetchPaginatedSpecies(ctx context.Context, page, limit int64) (*PaginatedSpecies, error)
+}
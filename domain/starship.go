@@ -0,0 +1,43 @@
+package domain
+
+import (
+	"context"
+
+	"github.com/Kamva/mgm/v2"
+	mongopagination "github.com/gobeam/mongo-go-pagination"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type Starship struct {
+	mgm.DefaultModel     `bson:",inline"`
+	SourceResource       `bson:",inline"`
+	Name                 string               `json:"name" bson:"name"`
+	Model                string               `json:"model" bson:"model"`
+	Manufacturer         string               `json:"manufacturer" bson:"manufacturer"`
+	CostInCredits        string               `json:"cost_in_credits" bson:"cost_in_credits"`
+	Length               string               `json:"length" bson:"length"`
+	MaxAtmospheringSpeed string               `json:"max_atmosphering_speed" bson:"max_atmosphering_speed"`
+	Crew                 string               `json:"crew" bson:"crew"`
+	Passengers           string               `json:"passengers" bson:"passengers"`
+	CargoCapacity        string               `json:"cargo_capacity" bson:"cargo_capacity"`
+	Consumables          string               `json:"consumables" bson:"consumables"`
+	HyperdriveRating     string               `json:"hyperdrive_rating" bson:"hyperdrive_rating"`
+	MGLT                 string               `json:"MGLT" bson:"mglt"`
+	StarshipClass        string               `json:"starship_class" bson:"starship_class"`
+	Pilots               []string             `json:"pilots" bson:"pilots"`
+	PilotIds             []primitive.ObjectID `json:"pilot_ids,omitempty" bson:"pilot_ids,omitempty"`
+}
+
+func (m *Starship) CollectionName() string {
+	return string(ResourceStarships)
+}
+
+type PaginatedStarship struct {
+	Pagination *mongopagination.PaginatedData `json:"pagination" bson:"pagination"`
+	Data       []Starship                     `json:"data" bson:"data"`
+}
+
+type StarshipRepository interface {
+	GetById(ctx context.Context, id string) (*Starship, error)
+	FetchPaginatedStarships(ctx context.Context, page, limit int64) (*PaginatedStarship, error)
+}
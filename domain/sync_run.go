@@ -0,0 +1,44 @@
+package domain
+
+import (
+	"context"
+	"time"
+
+	"github.com/Kamva/mgm/v2"
+	mongopagination "github.com/gobeam/mongo-go-pagination"
+)
+
+// SyncRun records a single film sync of one source. Errors lists the items
+// that could not be saved, the run went on without them.
+type SyncRun struct {
+	mgm.DefaultModel  `bson:",inline"`
+	Source            string              `json:"source" bson:"source"`
+	StartedAt         time.Time           `json:"started_at" bson:"started_at"`
+	FinishedAt        time.Time           `json:"finished_at" bson:"finished_at"`
+	PagesFetched      int64               `json:"pages_fetched" bson:"pages_fetched"`
+	FilmsInserted     int64               `json:"films_inserted" bson:"films_inserted"`
+	FilmsUpdated      int64               `json:"films_updated" bson:"films_updated"`
+	FilmsUnchanged    int64               `json:"films_unchanged" bson:"films_unchanged"`
+	FilmsRetired      int64               `json:"films_retired" bson:"films_retired"`
+	HashBefore        string              `json:"hash_before" bson:"hash_before"`
+	HashAfter         string              `json:"hash_after" bson:"hash_after"`
+	Changes           []FilmChange        `json:"changes,omitempty" bson:"changes,omitempty"`
+	Resources         []ResourceSyncStats `json:"resources,omitempty" bson:"resources,omitempty"`
+	RelationsResolved int64               `json:"relations_resolved" bson:"relations_resolved"`
+	Error             string              `json:"error,omitempty" bson:"error,omitempty"`
+	Errors            []string            `json:"errors,omitempty" bson:"errors,omitempty"`
+}
+
+func (m *SyncRun) CollectionName() string {
+	return "sync_runs"
+}
+
+type PaginatedSyncRun struct {
+	Pagination *mongopagination.PaginatedData `json:"pagination" bson:"pagination"`
+	Data       []SyncRun                      `json:"data" bson:"data"`
+}
+
+type SyncRunRepository interface {
+	Create(ctx context.Context, run *SyncRun) (*SyncRun, error)
+	FetchPaginatedSyncRuns(ctx context.Context, page, limit int64) (*PaginatedSyncRun, error)
+}
@@ -18,7 +18,14 @@ type User struct {
 	Lastname         string     `json:"lastname" bson:"lastname"`
 	Email            string     `json:"email" bson:"email"`
 	Password         string     `json:"password,omitempty" bson:"password"`
+	Role             string     `json:"role" bson:"role"`
 	DeletedAt        *time.Time `json:"deleted_at,omitempty" bson:"deleted_at"`
+	BannedAt         *time.Time `json:"banned_at,omitempty" bson:"banned_at,omitempty"`
+	EmailVerifiedAt  *time.Time `json:"email_verified_at,omitempty" bson:"email_verified_at,omitempty"`
+}
+
+func (u *User) IsEmailVerified() bool {
+	return u.EmailVerifiedAt != nil
 }
 
 func (u *User) Default() interface{} {
@@ -30,7 +37,7 @@ func (u *User) Default() interface{} {
 type SignupRequest struct {
 	Firstname string `validate:"required" json:"firstname" bson:"firstname"`
 	Lastname  string `validate:"required" json:"lastname" bson:"lastname"`
-	Email     string `validate:"required" json:"email" bson:"email"`
+	Email     string `validate:"required,email" json:"email" bson:"email"`
 	Password  string `validate:"required" json:"password" bson:"password"`
 }
 
@@ -43,9 +50,30 @@ type UserRepository interface {
 	GetByEmail(ctx context.Context, email string) (*User, error)
 	Create(ctx context.Context, user *User) (*User, error)
 	GetById(ctx context.Context, userId string) (*User, error)
+	Ban(ctx context.Context, userId string) error
+	SetRole(ctx context.Context, userId, role string) (*User, error)
+	MarkEmailVerified(ctx context.Context, userId string) error
+}
+
+type SetRoleRequest struct {
+	Role string `validate:"required,oneof=user moderator admin" json:"role"`
 }
 
 type UserUsecase interface {
 	Login(ctx context.Context, reqBody *LoginRequest) (*User, error)
 	Signup(ctx context.Context, reqBody *SignupRequest) (*User, error)
+	// IssueTokens starts a new refresh token family for the user.
+	IssueTokens(ctx context.Context, user *User) (*TokenPair, error)
+	// RefreshTokens rotates the refresh token. Reusing a rotated token
+	// revokes its whole family.
+	RefreshTokens(ctx context.Context, refreshToken string) (*TokenPair, error)
+	// Logout revokes the access token and, when given, the refresh token
+	// family.
+	Logout(ctx context.Context, userId, jti string, expiresAt time.Time, refreshToken string) error
+	// RevokeSessions revokes every access and refresh token of the user.
+	RevokeSessions(ctx context.Context, userId string) error
+	// VerifyEmail verifies the email of the user the token was sent to.
+	VerifyEmail(ctx context.Context, token string) (*User, error)
+	// ResendVerification sends the user a new verification token.
+	ResendVerification(ctx context.Context, userId string) error
 }
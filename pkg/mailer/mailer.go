@@ -0,0 +1,159 @@
+package mailer
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"net/smtp"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+	"movies-review-api/domain"
+)
+
+// SMTPMailer sends emails through an SMTP server.
+type SMTPMailer struct {
+	Host     string
+	Port     string
+	Username string
+	Password string
+	From     string
+}
+
+// smtpTimeout bounds a delivery when ctx has no earlier deadline.
+const smtpTimeout = 30 * time.Second
+
+func (m SMTPMailer) Send(ctx context.Context, message domain.Message) error {
+	ctx, cancel := context.WithTimeout(ctx, smtpTimeout)
+	defer cancel()
+
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", net.JoinHostPort(m.Host, m.Port))
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	// the deadline covers the whole exchange, not only the dial
+	deadline, _ := ctx.Deadline()
+	if err = conn.SetDeadline(deadline); err != nil {
+		return err
+	}
+
+	client, err := smtp.NewClient(conn, m.Host)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	if ok, _ := client.Extension("STARTTLS"); ok {
+		if err = client.StartTLS(&tls.Config{ServerName: m.Host}); err != nil {
+			return err
+		}
+	}
+
+	if m.Username != "" {
+		if err = client.Auth(smtp.PlainAuth("", m.Username, m.Password, m.Host)); err != nil {
+			return err
+		}
+	}
+
+	if err = client.Mail(m.From); err != nil {
+		return err
+	}
+	if err = client.Rcpt(message.To); err != nil {
+		return err
+	}
+
+	w, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err = w.Write(m.format(message)); err != nil {
+		return err
+	}
+	if err = w.Close(); err != nil {
+		return err
+	}
+
+	return client.Quit()
+}
+
+// headerValue drops line breaks so values cannot add headers.
+var headerValue = strings.NewReplacer("\r", "", "\n", "")
+
+func (m SMTPMailer) format(message domain.Message) []byte {
+	var b strings.Builder
+	fmt.Fprintf(&b, "From: %s\r\n", headerValue.Replace(m.From))
+	fmt.Fprintf(&b, "To: %s\r\n", headerValue.Replace(message.To))
+	fmt.Fprintf(&b, "Subject: %s\r\n", headerValue.Replace(message.Subject))
+	fmt.Fprintf(&b, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=UTF-8\r\n\r\n")
+	b.WriteString(strings.ReplaceAll(message.Body, "\n", "\r\n"))
+	return []byte(b.String())
+}
+
+// LogMailer appends emails to a file, or logs them when no file is set,
+// for local runs.
+type LogMailer struct {
+	Logger *zap.Logger
+	Path   string
+
+	mu sync.Mutex
+}
+
+func (m *LogMailer) Send(ctx context.Context, message domain.Message) error {
+	if m.Path == "" {
+		m.Logger.Info("email sent",
+			zap.String("to", message.To),
+			zap.String("subject", message.Subject),
+			zap.String("body", message.Body))
+		return nil
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	f, err := os.OpenFile(m.Path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	_, err = fmt.Fprintf(f, "To: %s\nSubject: %s\nDate: %s\n\n%s\n\n",
+		message.To, message.Subject, time.Now().Format(time.RFC1123Z), message.Body)
+	return err
+}
+
+// New builds the mailer from the config. MAILER=smtp sends through
+// SMTP_HOST, SMTP_PORT (587 by default), SMTP_USERNAME and SMTP_PASSWORD
+// from MAIL_FROM. MAILER=log writes emails to MAILER_FILE, or logs them.
+// The log mailer exposes verification links, so it is never picked by
+// default.
+func New(logger *zap.Logger) (domain.Mailer, error) {
+	switch os.Getenv("MAILER") {
+	case "smtp":
+		port := os.Getenv("SMTP_PORT")
+		if port == "" {
+			port = "587"
+		}
+
+		return SMTPMailer{
+			Host:     os.Getenv("SMTP_HOST"),
+			Port:     port,
+			Username: os.Getenv("SMTP_USERNAME"),
+			Password: os.Getenv("SMTP_PASSWORD"),
+			From:     os.Getenv("MAIL_FROM"),
+		}, nil
+	case "log":
+		return &LogMailer{Logger: logger, Path: os.Getenv("MAILER_FILE")}, nil
+	}
+
+	return nil, errors.New("MAILER must be smtp or log")
+}
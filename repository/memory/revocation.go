@@ -0,0 +1,74 @@
+// Package memory holds in-memory implementations of the domain stores, for
+// single instance deployments and local runs.
+package memory
+
+import (
+	"context"
+	"movies-review-api/domain"
+	"sync"
+	"time"
+)
+
+type memoryRevocationStore struct {
+	mu     sync.RWMutex
+	tokens map[string]time.Time
+	users  map[string]time.Time
+	// tokenTTL is the longest access token lifetime, after which a user
+	// revocation covers no valid token.
+	tokenTTL time.Duration
+}
+
+func (m *memoryRevocationStore) RevokeToken(ctx context.Context, jti string, expiresAt time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	// drop the tokens that expired since they no longer need revoking
+	now := time.Now()
+	for id, exp := range m.tokens {
+		if now.After(exp) {
+			delete(m.tokens, id)
+		}
+	}
+
+	m.tokens[jti] = expiresAt
+	return nil
+}
+
+func (m *memoryRevocationStore) RevokeUser(ctx context.Context, userId string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	// drop the user revocations older than every token still valid
+	now := time.Now().UTC()
+	for id, revokedAt := range m.users {
+		if now.Sub(revokedAt) > m.tokenTTL {
+			delete(m.users, id)
+		}
+	}
+
+	m.users[userId] = now
+	return nil
+}
+
+func (m *memoryRevocationStore) IsRevoked(ctx context.Context, jti, userId string, issuedAt time.Time) (bool, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if _, ok := m.tokens[jti]; ok && jti != "" {
+		return true, nil
+	}
+
+	if revokedAt, ok := m.users[userId]; ok {
+		return domain.IssuedUpTo(issuedAt, revokedAt), nil
+	}
+
+	return false, nil
+}
+
+func NewRevocationStore() domain.RevocationStore {
+	return &memoryRevocationStore{
+		tokens:   map[string]time.Time{},
+		users:    map[string]time.Time{},
+		tokenTTL: domain.AccessTokenTTL(),
+	}
+}
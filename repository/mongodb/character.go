@@ -0,0 +1,141 @@
+package mongodb
+
+import (
+	"context"
+	"errors"
+	mongopagination "github.com/gobeam/mongo-go-pagination"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"movies-review-api/domain"
+
+	"github.com/Kamva/mgm/v2"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.uber.org/zap"
+)
+
+type mongoCharacterRepository struct {
+	Logger *zap.Logger
+	Coll   *mgm.Collection
+}
+
+func (m *mongoCharacterRepository) FetchPaginatedCharacters(ctx context.Context, page, limit int64) (*domain.PaginatedCharacter, error) {
+
+	var characters []domain.Character
+
+	filter := bson.D{}
+
+	paginatedData, err := mongopagination.New(m.Coll.Collection).
+		Context(ctx).
+		Limit(limit).
+		Page(page).
+		Sort("name", 1).
+		Filter(filter).
+		Decode(&characters).
+		Find()
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &domain.PaginatedCharacter{
+		Data:       characters,
+		Pagination: paginatedData,
+	}, nil
+}
+
+func (m *mongoCharacterRepository) FetchPaginatedFilmCharacters(ctx context.Context, ids []primitive.ObjectID, query domain.FilmCharactersQuery, page, limit int64) (*domain.PaginatedCharacter, error) {
+
+	var characters []domain.Character
+
+	if ids == nil {
+		ids = []primitive.ObjectID{}
+	}
+
+	filter := bson.M{"_id": bson.M{"$in": ids}}
+	if query.Gender != "" {
+		filter["gender"] = query.Gender
+	}
+
+	sortField := "name"
+	if query.Sort == "height" {
+		sortField = "height_cm"
+	}
+
+	sortOrder := 1
+	if query.Order == "desc" {
+		sortOrder = -1
+	}
+
+	paginatedData, err := mongopagination.New(m.Coll.Collection).
+		Context(ctx).
+		Limit(limit).
+		Page(page).
+		Sort(sortField, sortOrder).
+		Sort("_id", sortOrder).
+		Filter(filter).
+		Decode(&characters).
+		Find()
+
+	if err != nil {
+		return nil, err
+	}
+
+	cursor, err := m.Coll.Aggregate(ctx, bson.A{
+		bson.M{"$match": filter},
+		bson.M{"$group": bson.M{
+			"_id":             nil,
+			"count":           bson.M{"$sum": 1},
+			"total_height_cm": bson.M{"$sum": "$height_cm"},
+		}},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var totals []struct {
+		Count         int64 `bson:"count"`
+		TotalHeightCm int64 `bson:"total_height_cm"`
+	}
+	if err = cursor.All(ctx, &totals); err != nil {
+		return nil, err
+	}
+
+	metadata := domain.NewCharacterMetadata(0, 0)
+	if len(totals) > 0 {
+		metadata = domain.NewCharacterMetadata(totals[0].Count, totals[0].TotalHeightCm)
+	}
+
+	return &domain.PaginatedCharacter{
+		Data:       characters,
+		Pagination: paginatedData,
+		Metadata:   metadata,
+	}, nil
+}
+
+func (m *mongoCharacterRepository) GetById(ctx context.Context, id string) (*domain.Character, error) {
+	var character domain.Character
+
+	primitiveId, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, errors.New("invalid resource id")
+	}
+
+	err = m.Coll.FindByIDWithCtx(ctx, primitiveId, &character)
+
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, errors.New("resource not found")
+		}
+		m.Logger.Error(err.Error(), zap.Error(err))
+		return nil, err
+	}
+
+	return &character, nil
+}
+
+func NewCharacterRepository(logger *zap.Logger) domain.CharacterRepository {
+	return &mongoCharacterRepository{
+		Logger: logger,
+		Coll:   mgm.Coll(&domain.Character{}),
+	}
+}
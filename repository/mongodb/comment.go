@@ -2,13 +2,16 @@ package mongodb
 
 import (
 	"context"
+	"errors"
 	mongopagination "github.com/gobeam/mongo-go-pagination"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
 	"movies-review-api/domain"
+	"time"
 
 	"github.com/Kamva/mgm/v2"
 	"go.mongodb.org/mongo-driver/bson"
-	//"go.mongodb.org/mongo-driver/mongo/options"
+	"go.mongodb.org/mongo-driver/mongo/options"
 	"go.uber.org/zap"
 )
 
@@ -17,18 +20,24 @@ type mongoCommentRepository struct {
 	Coll   *mgm.Collection
 }
 
-func (m *mongoCommentRepository) FetchPaginatedFilmComments(ctx context.Context, filmId string, page, limit int64) (*domain.PaginatedComment, error) {
+func (m *mongoCommentRepository) FetchPaginatedFilmComments(ctx context.Context, filmId, sort string, page, limit int64) (*domain.PaginatedComment, error) {
 
 	var comment []domain.Comment
 
 	collection := mgm.Coll(&domain.Comment{}).Collection
 
-	filter := bson.M{"film_id": filmId}
+	filter := filmCommentsFilter(filmId)
 
-	paginatedData, err := mongopagination.New(collection).
+	query := mongopagination.New(collection).
 		Context(ctx).
 		Limit(limit).
-		Page(page).
+		Page(page)
+
+	if sort == domain.CommentSortTop {
+		query = query.Sort("reaction_score", -1)
+	}
+
+	paginatedData, err := query.
 		Sort("created_at", 1).
 		Filter(filter).
 		Decode(&comment).
@@ -44,8 +53,35 @@ func (m *mongoCommentRepository) FetchPaginatedFilmComments(ctx context.Context,
 	}, nil
 }
 
+func (m *mongoCommentRepository) FetchCursorFilmComments(ctx context.Context, filmId string, cursor *domain.Cursor, limit int64) (*domain.PaginatedComment, error) {
+
+	filter := filmCommentsFilter(filmId)
+
+	docs, pagination, err := findCursorPage(ctx, m.Coll.Collection, filter, cursor, limit, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	comments := make([]domain.Comment, 0, len(docs))
+	for _, doc := range docs {
+		var comment domain.Comment
+		if err = bson.Unmarshal(doc, &comment); err != nil {
+			return nil, err
+		}
+		comments = append(comments, comment)
+	}
+
+	return &domain.PaginatedComment{
+		Data:   comments,
+		Cursor: pagination,
+	}, nil
+}
+
 func (m mongoCommentRepository) Create(ctx context.Context, comment *domain.Comment) (*domain.Comment, error) {
 
+	// revision 1 is saved by the Created hook
+	comment.Revision = 1
+
 	mgm.TransactionWithCtx(ctx, func(session mongo.Session, sc mongo.SessionContext) error {
 
 		cmnt, err := func(ctx context.Context, comment *domain.Comment) (*domain.Comment, error) {
@@ -64,13 +100,309 @@ func (m mongoCommentRepository) Create(ctx context.Context, comment *domain.Comm
 			return err
 		}
 
-		comment = cmnt;
+		comment = cmnt
 		return session.CommitTransaction(ctx)
 	})
 
 	return comment, nil
 }
 
+func (m *mongoCommentRepository) GetById(ctx context.Context, id string) (*domain.Comment, error) {
+	var comment domain.Comment
+
+	primitiveId, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, errors.New("invalid resource id")
+	}
+
+	err = m.Coll.FirstWithCtx(ctx, bson.M{"_id": primitiveId, "deleted_at": nil}, &comment)
+
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, errors.New("resource not found")
+		}
+		m.Logger.Error(err.Error(), zap.Error(err))
+		return nil, err
+	}
+
+	return &comment, nil
+}
+
+func (m *mongoCommentRepository) Update(ctx context.Context, comment *domain.Comment) (*domain.Comment, error) {
+
+	if err := domain.BackfillCommentRevision(ctx, comment.ID); err != nil {
+		m.Logger.Error(err.Error(), zap.Error(err))
+		return nil, err
+	}
+
+	comment.UpdatedAt = time.Now().UTC()
+
+	editorId, editedAt := comment.EditedBy, comment.UpdatedAt
+	if editorId == "" {
+		editorId = comment.UserId
+	}
+	if comment.EditedAt != nil {
+		editedAt = *comment.EditedAt
+	}
+
+	// the next revision number is reserved on the comment, so concurrent
+	// edits never share one
+	var reserved domain.Comment
+	err := m.Coll.FindOneAndUpdate(ctx,
+		bson.M{"_id": comment.ID, "deleted_at": nil, "summary": bson.M{"$ne": comment.Summary}},
+		bson.M{"$inc": bson.M{"revision": 1}},
+		options.FindOneAndUpdate().SetReturnDocument(options.After)).Decode(&reserved)
+
+	if err == mongo.ErrNoDocuments {
+		// the summary did not change, or the comment was deleted by a
+		// concurrent request
+		count, err := m.Coll.CountDocuments(ctx, bson.M{"_id": comment.ID, "deleted_at": nil})
+		if err != nil {
+			m.Logger.Error(err.Error(), zap.Error(err))
+			return nil, err
+		}
+		if count == 0 {
+			return nil, errors.New("resource not found")
+		}
+		return comment, nil
+	}
+	if err != nil {
+		m.Logger.Error(err.Error(), zap.Error(err))
+		return nil, err
+	}
+
+	// the revision is saved before the summary is shown, so every summary
+	// shown has its revision
+	err = domain.SaveCommentRevision(ctx, comment.ID.Hex(), reserved.Revision, comment.Summary, editorId, editedAt)
+	if err != nil {
+		m.Logger.Error(err.Error(), zap.Error(err))
+		return nil, err
+	}
+	comment.Revision = reserved.Revision
+
+	// only the edit is set so concurrent reactions, reports and moderation
+	// are kept, and a later edit that reserved the next revision wins
+	_, err = m.Coll.UpdateOne(ctx,
+		bson.M{"_id": comment.ID, "revision": reserved.Revision},
+		bson.M{"$set": bson.M{
+			"summary":    comment.Summary,
+			"edited_at":  comment.EditedAt,
+			"edited_by":  comment.EditedBy,
+			"updated_at": comment.UpdatedAt,
+		}})
+
+	if err != nil {
+		m.Logger.Error(err.Error(), zap.Error(err))
+		return nil, err
+	}
+
+	return comment, nil
+}
+
+func (m *mongoCommentRepository) SoftDelete(ctx context.Context, comment *domain.Comment) error {
+	now := time.Now().UTC()
+
+	result, err := m.Coll.UpdateOne(ctx,
+		bson.M{"_id": comment.ID, "deleted_at": nil},
+		bson.M{"$set": bson.M{"deleted_at": now, "updated_at": now}})
+
+	if err != nil {
+		m.Logger.Error(err.Error(), zap.Error(err))
+		return err
+	}
+
+	// already deleted by a concurrent request
+	if result.ModifiedCount == 0 {
+		return errors.New("resource not found")
+	}
+
+	comment.DeletedAt = &now
+	return comment.SoftDeleted()
+}
+
+func (m *mongoCommentRepository) FetchPaginatedCommentReplies(ctx context.Context, parentId string, page, limit int64) (*domain.PaginatedComment, error) {
+
+	var comment []domain.Comment
+
+	filter := bson.M{"parent_id": parentId, "deleted_at": nil, "status": bson.M{"$nin": domain.HiddenCommentStatuses}}
+
+	paginatedData, err := mongopagination.New(m.Coll.Collection).
+		Context(ctx).
+		Limit(limit).
+		Page(page).
+		Sort("created_at", 1).
+		Filter(filter).
+		Decode(&comment).
+		Find()
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &domain.PaginatedComment{
+		Data:       comment,
+		Pagination: paginatedData,
+	}, nil
+}
+
+func (m *mongoCommentRepository) FetchPaginatedHeldComments(ctx context.Context, page, limit int64) (*domain.PaginatedComment, error) {
+
+	var comment []domain.Comment
+
+	filter := bson.M{"status": domain.CommentStatusHeld, "deleted_at": nil}
+
+	paginatedData, err := mongopagination.New(m.Coll.Collection).
+		Context(ctx).
+		Limit(limit).
+		Page(page).
+		Sort("created_at", 1).
+		Filter(filter).
+		Decode(&comment).
+		Find()
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &domain.PaginatedComment{
+		Data:       comment,
+		Pagination: paginatedData,
+	}, nil
+}
+
+func (m *mongoCommentRepository) HasRecentDuplicate(ctx context.Context, comment *domain.Comment, since time.Time) (bool, error) {
+	filter := bson.M{
+		"film_id":    comment.FilmId,
+		"summary":    comment.Summary,
+		"created_at": bson.M{"$gte": since},
+		"deleted_at": nil,
+	}
+
+	// anonymous comments are told apart by the ip they were posted from
+	if comment.UserId != "" {
+		filter["user_id"] = comment.UserId
+	} else {
+		filter["user_id"] = ""
+		filter["author_ip"] = comment.AuthorIp
+	}
+
+	if !comment.ID.IsZero() {
+		filter["_id"] = bson.M{"$ne": comment.ID}
+	}
+
+	count, err := m.Coll.CountDocuments(ctx, filter, options.Count().SetLimit(1))
+
+	if err != nil {
+		m.Logger.Error(err.Error(), zap.Error(err))
+		return false, err
+	}
+
+	return count > 0, nil
+}
+
+func (m *mongoCommentRepository) FetchPaginatedReportedComments(ctx context.Context, page, limit int64) (*domain.PaginatedComment, error) {
+
+	var comment []domain.Comment
+
+	filter := bson.M{"report_count": bson.M{"$gt": 0}, "deleted_at": nil}
+
+	paginatedData, err := mongopagination.New(m.Coll.Collection).
+		Context(ctx).
+		Limit(limit).
+		Page(page).
+		Sort("report_count", -1).
+		Sort("created_at", 1).
+		Filter(filter).
+		Decode(&comment).
+		Find()
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &domain.PaginatedComment{
+		Data:       comment,
+		Pagination: paginatedData,
+	}, nil
+}
+
+func (m *mongoCommentRepository) Approve(ctx context.Context, comment *domain.Comment) error {
+	now := time.Now().UTC()
+
+	result, err := m.Coll.UpdateOne(ctx,
+		bson.M{"_id": comment.ID, "status": bson.M{"$in": domain.HiddenCommentStatuses}, "deleted_at": nil},
+		bson.M{"$set": bson.M{"status": domain.CommentStatusApproved, "updated_at": now}})
+
+	if err != nil {
+		m.Logger.Error(err.Error(), zap.Error(err))
+		return err
+	}
+
+	// already approved by a concurrent request
+	if result.ModifiedCount == 0 {
+		return errors.New("resource not found")
+	}
+
+	comment.Status = domain.CommentStatusApproved
+	comment.UpdatedAt = now
+	return comment.Published()
+}
+
+func (m *mongoCommentRepository) Hold(ctx context.Context, comment *domain.Comment, reasons []string) error {
+	comment.Status = domain.CommentStatusHeld
+	comment.ModerationReasons = reasons
+
+	return m.unpublish(ctx, comment, bson.M{"status": comment.Status, "moderation_reasons": reasons})
+}
+
+func (m *mongoCommentRepository) Hide(ctx context.Context, comment *domain.Comment) error {
+	comment.Status = domain.CommentStatusHidden
+
+	return m.unpublish(ctx, comment, bson.M{"status": comment.Status})
+}
+
+func (m *mongoCommentRepository) HideReported(ctx context.Context, comment *domain.Comment) error {
+	comment.Status = domain.CommentStatusReported
+
+	return m.unpublish(ctx, comment, bson.M{"status": comment.Status})
+}
+
+// unpublish sets the hidden status of the comment and takes it off the
+// counts when it was still visible.
+func (m *mongoCommentRepository) unpublish(ctx context.Context, comment *domain.Comment, set bson.M) error {
+	comment.UpdatedAt = time.Now().UTC()
+	set["updated_at"] = comment.UpdatedAt
+
+	result, err := m.Coll.UpdateOne(ctx,
+		bson.M{"_id": comment.ID, "status": bson.M{"$nin": domain.HiddenCommentStatuses}, "deleted_at": nil},
+		bson.M{"$set": set})
+
+	if err != nil {
+		m.Logger.Error(err.Error(), zap.Error(err))
+		return err
+	}
+
+	if result.ModifiedCount > 0 {
+		return comment.Unpublished()
+	}
+
+	// already hidden, only its status changes
+	_, err = m.Coll.UpdateOne(ctx, bson.M{"_id": comment.ID, "deleted_at": nil}, bson.M{"$set": set})
+
+	if err != nil {
+		m.Logger.Error(err.Error(), zap.Error(err))
+		return err
+	}
+
+	return nil
+}
+
+// filmCommentsFilter matches the visible top level comments of a film.
+// Comments saved before moderation have no status and are visible.
+func filmCommentsFilter(filmId string) bson.M {
+	return bson.M{"film_id": filmId, "parent_id": nil, "deleted_at": nil, "status": bson.M{"$nin": domain.HiddenCommentStatuses}}
+}
+
 func NewCommentRepository(logger *zap.Logger) domain.CommentRepository {
 	return &mongoCommentRepository{
 		Logger: logger,
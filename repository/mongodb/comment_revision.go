@@ -0,0 +1,48 @@
+package mongodb
+
+import (
+	"context"
+	mongopagination "github.com/gobeam/mongo-go-pagination"
+	"movies-review-api/domain"
+
+	"github.com/Kamva/mgm/v2"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.uber.org/zap"
+)
+
+type mongoCommentRevisionRepository struct {
+	Logger *zap.Logger
+	Coll   *mgm.Collection
+}
+
+func (m *mongoCommentRevisionRepository) FetchPaginatedCommentRevisions(ctx context.Context, commentId string, page, limit int64) (*domain.PaginatedCommentRevision, error) {
+
+	var revisions []domain.CommentRevision
+
+	filter := bson.M{"comment_id": commentId}
+
+	paginatedData, err := mongopagination.New(m.Coll.Collection).
+		Context(ctx).
+		Limit(limit).
+		Page(page).
+		Sort("revision", 1).
+		Filter(filter).
+		Decode(&revisions).
+		Find()
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &domain.PaginatedCommentRevision{
+		Data:       revisions,
+		Pagination: paginatedData,
+	}, nil
+}
+
+func NewCommentRevisionRepository(logger *zap.Logger) domain.CommentRevisionRepository {
+	return &mongoCommentRevisionRepository{
+		Logger: logger,
+		Coll:   mgm.Coll(&domain.CommentRevision{}),
+	}
+}
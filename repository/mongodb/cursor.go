@@ -0,0 +1,98 @@
+package mongodb
+
+import (
+	"context"
+	"movies-review-api/domain"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// findCursorPage returns up to limit documents matching filter after, or
+// before, cursor in created_at, _id order, along with the cursors of the
+// pages next to it. A nil cursor starts at the first page.
+func findCursorPage(ctx context.Context, coll *mongo.Collection, filter bson.M, cursor *domain.Cursor, limit int64, opts *options.FindOptions) ([]bson.Raw, *domain.CursorPagination, error) {
+	if limit <= 0 {
+		limit = 20
+	}
+
+	sortOrder := 1
+	if cursor != nil && cursor.Before {
+		sortOrder = -1
+	}
+
+	query := filter
+	if cursor != nil {
+		op := "$gt"
+		if cursor.Before {
+			op = "$lt"
+		}
+		query = bson.M{"$and": bson.A{
+			filter,
+			bson.M{"$or": bson.A{
+				bson.M{"created_at": bson.M{op: cursor.CreatedAt}},
+				bson.M{"created_at": cursor.CreatedAt, "_id": bson.M{op: cursor.Id}},
+			}},
+		}}
+	}
+
+	if opts == nil {
+		opts = options.Find()
+	}
+	opts.SetSort(bson.D{{Key: "created_at", Value: sortOrder}, {Key: "_id", Value: sortOrder}}).
+		SetLimit(limit + 1)
+
+	result, err := coll.Find(ctx, query, opts)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var docs []bson.Raw
+	if err = result.All(ctx, &docs); err != nil {
+		return nil, nil, err
+	}
+
+	hasMore := int64(len(docs)) > limit
+	if hasMore {
+		docs = docs[:limit]
+	}
+
+	// pages before a cursor are read backwards
+	if sortOrder == -1 {
+		for i, j := 0, len(docs)-1; i < j; i, j = i+1, j-1 {
+			docs[i], docs[j] = docs[j], docs[i]
+		}
+	}
+
+	pagination := &domain.CursorPagination{Limit: limit}
+	if len(docs) == 0 {
+		return docs, pagination, nil
+	}
+
+	hasNext, hasPrev := hasMore, cursor != nil
+	if cursor != nil && cursor.Before {
+		hasNext, hasPrev = true, hasMore
+	}
+
+	if hasNext {
+		pagination.NextCursor = cursorOf(docs[len(docs)-1], false).Encode()
+	}
+	if hasPrev {
+		pagination.PrevCursor = cursorOf(docs[0], true).Encode()
+	}
+
+	return docs, pagination, nil
+}
+
+func cursorOf(doc bson.Raw, before bool) domain.Cursor {
+	var key struct {
+		Id        primitive.ObjectID `bson:"_id"`
+		CreatedAt time.Time          `bson:"created_at"`
+	}
+	_ = bson.Unmarshal(doc, &key)
+
+	return domain.Cursor{CreatedAt: key.CreatedAt, Id: key.Id, Before: before}
+}
@@ -0,0 +1,69 @@
+package mongodb
+
+import (
+	"context"
+	"errors"
+	"go.mongodb.org/mongo-driver/mongo"
+	"movies-review-api/domain"
+	"time"
+
+	"github.com/Kamva/mgm/v2"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.uber.org/zap"
+)
+
+type mongoEmailVerificationRepository struct {
+	Logger *zap.Logger
+	Coll   *mgm.Collection
+}
+
+func (m *mongoEmailVerificationRepository) Create(ctx context.Context, verification *domain.EmailVerification) (*domain.EmailVerification, error) {
+
+	err := m.Coll.CreateWithCtx(ctx, verification)
+
+	if err != nil {
+		m.Logger.Error(err.Error(), zap.Error(err))
+		return nil, err
+	}
+
+	return verification, nil
+}
+
+func (m *mongoEmailVerificationRepository) GetByHash(ctx context.Context, tokenHash string) (*domain.EmailVerification, error) {
+	var verification domain.EmailVerification
+
+	err := m.Coll.FirstWithCtx(ctx, bson.M{"token_hash": tokenHash}, &verification)
+
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, errors.New("resource not found")
+		}
+		m.Logger.Error(err.Error(), zap.Error(err))
+		return nil, err
+	}
+
+	return &verification, nil
+}
+
+func (m *mongoEmailVerificationRepository) MarkUsed(ctx context.Context, verification *domain.EmailVerification) (bool, error) {
+	now := time.Now().UTC()
+
+	result, err := m.Coll.UpdateOne(ctx,
+		bson.M{"_id": verification.ID, "used_at": nil},
+		bson.M{"$set": bson.M{"used_at": now, "updated_at": now}})
+
+	if err != nil {
+		m.Logger.Error(err.Error(), zap.Error(err))
+		return false, err
+	}
+
+	verification.UsedAt = &now
+	return result.ModifiedCount > 0, nil
+}
+
+func NewEmailVerificationRepository(logger *zap.Logger) domain.EmailVerificationRepository {
+	return &mongoEmailVerificationRepository{
+		Logger: logger,
+		Coll:   mgm.Coll(&domain.EmailVerification{}),
+	}
+}
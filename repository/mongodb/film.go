@@ -6,7 +6,10 @@ import (
 	mongopagination "github.com/gobeam/mongo-go-pagination"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 	"movies-review-api/domain"
+	"regexp"
+	"strconv"
 
 	"github.com/Kamva/mgm/v2"
 	"go.mongodb.org/mongo-driver/bson"
@@ -19,22 +22,38 @@ type mongoFilmRepository struct {
 	Coll   *mgm.Collection
 }
 
-func (m *mongoFilmRepository) FetchPaginatedFilms(ctx context.Context, page, limit int64) (*domain.PaginatedFilm, error) {
+func (m *mongoFilmRepository) FetchPaginatedFilms(ctx context.Context, query domain.FilmQuery, page, limit int64) (*domain.PaginatedFilm, error) {
 
 	var films []domain.Film
 
 	collection := mgm.Coll(&domain.Film{}).Collection
 
-	filter := bson.D{}
+	filter := filmQueryFilter(query)
 
-	paginatedData, err := mongopagination.New(collection).
+	sortField := query.Sort
+	if sortField == "" {
+		sortField = "release_date"
+	}
+
+	sortOrder := 1
+	if query.Order == "desc" {
+		sortOrder = -1
+	}
+
+	paginatedQuery := mongopagination.New(collection).
 		Context(ctx).
 		Limit(limit).
 		Page(page).
-		Sort("release_date", 1).
+		Sort(sortField, sortOrder).
+		Sort("_id", sortOrder).
 		Filter(filter).
-		Decode(&films).
-		Find()
+		Decode(&films)
+
+	if len(query.Fields) > 0 {
+		paginatedQuery = paginatedQuery.Select(filmProjection(query.Fields...))
+	}
+
+	paginatedData, err := paginatedQuery.Find()
 
 	if err != nil {
 		return nil, err
@@ -46,6 +65,109 @@ func (m *mongoFilmRepository) FetchPaginatedFilms(ctx context.Context, page, lim
 	}, nil
 }
 
+func (m *mongoFilmRepository) FetchCursorFilms(ctx context.Context, query domain.FilmQuery, cursor *domain.Cursor, limit int64) (*domain.PaginatedFilm, error) {
+
+	opts := options.Find()
+	if len(query.Fields) > 0 {
+		// created_at is needed to build the next cursors
+		opts.SetProjection(filmProjection(append([]string{"created_at"}, query.Fields...)...))
+	}
+
+	docs, pagination, err := findCursorPage(ctx, m.Coll.Collection, filmQueryFilter(query), cursor, limit, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	films := make([]domain.Film, 0, len(docs))
+	for _, doc := range docs {
+		var film domain.Film
+		if err = bson.Unmarshal(doc, &film); err != nil {
+			return nil, err
+		}
+		films = append(films, film)
+	}
+
+	return &domain.PaginatedFilm{
+		Data:   films,
+		Cursor: pagination,
+	}, nil
+}
+
+// filmProjection projects on each field once, mongo rejects a field
+// projected twice.
+func filmProjection(fields ...string) bson.D {
+	projection := bson.D{}
+	seen := map[string]bool{}
+	for _, field := range fields {
+		if seen[field] {
+			continue
+		}
+		seen[field] = true
+		projection = append(projection, bson.E{Key: field, Value: 1})
+	}
+	return projection
+}
+
+func filmQueryFilter(query domain.FilmQuery) bson.M {
+	// films gone from their source stay reachable by id but are not listed
+	filter := bson.M{"retired_at": nil}
+
+	if query.Title != "" {
+		filter["title"] = primitive.Regex{Pattern: regexp.QuoteMeta(query.Title), Options: "i"}
+	}
+
+	if query.Search != "" {
+		filter["$text"] = bson.M{"$search": query.Search}
+	}
+
+	if query.Director != "" {
+		filter["director"] = primitive.Regex{Pattern: regexp.QuoteMeta(query.Director), Options: "i"}
+	}
+
+	releaseDate := bson.M{}
+	if query.ReleasedFrom != "" {
+		releaseDate["$gte"] = query.ReleasedFrom
+	}
+	if query.ReleasedTo != "" {
+		releaseDate["$lte"] = query.ReleasedTo
+	}
+	if len(releaseDate) > 0 {
+		filter["release_date"] = releaseDate
+	}
+
+	if query.MinComments != "" {
+		if minComments, err := strconv.ParseInt(query.MinComments, 10, 64); err == nil {
+			filter["comment_count"] = bson.M{"$gte": minComments}
+		}
+	}
+
+	return filter
+}
+
+func (m *mongoFilmRepository) SetAllowAnonymousComments(ctx context.Context, id string, allow bool) (*domain.Film, error) {
+	primitiveId, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, errors.New("invalid resource id")
+	}
+
+	var film domain.Film
+
+	err = m.Coll.FindOneAndUpdate(ctx,
+		bson.M{"_id": primitiveId},
+		bson.M{"$set": bson.M{"allow_anonymous_comments": allow}},
+		options.FindOneAndUpdate().SetReturnDocument(options.After)).Decode(&film)
+
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, errors.New("resource not found")
+		}
+		m.Logger.Error(err.Error(), zap.Error(err))
+		return nil, err
+	}
+
+	return &film, nil
+}
+
 func (m *mongoFilmRepository) GetById(ctx context.Context, id string) (*domain.Film, error) {
 	var film domain.Film
 
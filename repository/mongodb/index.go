@@ -0,0 +1,184 @@
+package mongodb
+
+import (
+	"context"
+	"errors"
+	"movies-review-api/domain"
+
+	"github.com/Kamva/mgm/v2"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// sourceIndex finds the documents synced from a source by their id there.
+// It is unique so concurrent syncs cannot insert a document twice.
+var sourceIndex = mongo.IndexModel{
+	Keys:    bson.D{{Key: "source", Value: 1}, {Key: "external_id", Value: 1}},
+	Options: options.Index().SetUnique(true).SetName("source_external_id_unique"),
+}
+
+// replacedIndexes were built before their unique replacement and are
+// dropped first, as mongodb refuses two indexes on the same keys.
+var replacedIndexes = []struct {
+	Model mgm.Model
+	Name  string
+}{
+	{Model: &domain.Film{}, Name: "source_1_external_id_1"},
+	{Model: &domain.StarwarsDataHash{}, Name: "source_1_page_url_1"},
+	{Model: &domain.CommentReport{}, Name: "comment_id_1_user_id_1"},
+}
+
+// indexes are the indexes every collection needs, keyed on the collection model.
+var indexes = []struct {
+	Model  mgm.Model
+	Models []mongo.IndexModel
+}{
+	{
+		Model: &domain.Film{},
+		Models: []mongo.IndexModel{
+			{Keys: bson.D{{Key: "title", Value: "text"}, {Key: "opening_crawl", Value: "text"}}, Options: options.Index().SetName("films_text")},
+			{Keys: bson.D{{Key: "title", Value: 1}, {Key: "_id", Value: 1}}},
+			{Keys: bson.D{{Key: "release_date", Value: 1}, {Key: "_id", Value: 1}}},
+			{Keys: bson.D{{Key: "comment_count", Value: 1}, {Key: "_id", Value: 1}}},
+			{Keys: bson.D{{Key: "rating_avg", Value: 1}, {Key: "_id", Value: 1}}},
+			{Keys: bson.D{{Key: "rating_count", Value: 1}, {Key: "_id", Value: 1}}},
+			{Keys: bson.D{{Key: "director", Value: 1}}},
+			sourceIndex,
+			{Keys: bson.D{{Key: "created_at", Value: 1}, {Key: "_id", Value: 1}}},
+		},
+	},
+	{Model: &domain.Character{}, Models: []mongo.IndexModel{sourceIndex}},
+	{Model: &domain.Planet{}, Models: []mongo.IndexModel{sourceIndex}},
+	{Model: &domain.Starship{}, Models: []mongo.IndexModel{sourceIndex}},
+	{Model: &domain.Vehicle{}, Models: []mongo.IndexModel{sourceIndex}},
+	{Model: &domain.Species{}, Models: []mongo.IndexModel{sourceIndex}},
+	{
+		Model: &domain.Comment{},
+		Models: []mongo.IndexModel{
+			{Keys: bson.D{{Key: "film_id", Value: 1}, {Key: "created_at", Value: 1}, {Key: "_id", Value: 1}}},
+			{Keys: bson.D{{Key: "parent_id", Value: 1}, {Key: "created_at", Value: 1}}},
+			{Keys: bson.D{{Key: "film_id", Value: 1}, {Key: "reaction_score", Value: -1}, {Key: "created_at", Value: 1}}},
+			{Keys: bson.D{{Key: "status", Value: 1}, {Key: "created_at", Value: 1}}},
+			{Keys: bson.D{{Key: "report_count", Value: -1}, {Key: "created_at", Value: 1}}},
+			{Keys: bson.D{{Key: "film_id", Value: 1}, {Key: "user_id", Value: 1}, {Key: "created_at", Value: -1}}},
+		},
+	},
+	{
+		Model: &domain.Rating{},
+		Models: []mongo.IndexModel{
+			{Keys: bson.D{{Key: "film_id", Value: 1}, {Key: "user_id", Value: 1}}, Options: options.Index().SetUnique(true)},
+		},
+	},
+	{
+		Model: &domain.Review{},
+		Models: []mongo.IndexModel{
+			{Keys: bson.D{{Key: "film_id", Value: 1}, {Key: "user_id", Value: 1}}, Options: options.Index().SetUnique(true)},
+			{Keys: bson.D{{Key: "film_id", Value: 1}, {Key: "created_at", Value: -1}}},
+			{Keys: bson.D{{Key: "film_id", Value: 1}, {Key: "helpful_count", Value: -1}, {Key: "created_at", Value: -1}}},
+		},
+	},
+	{
+		Model: &domain.ReviewVote{},
+		Models: []mongo.IndexModel{
+			{Keys: bson.D{{Key: "review_id", Value: 1}, {Key: "user_id", Value: 1}}, Options: options.Index().SetUnique(true)},
+		},
+	},
+	{
+		Model: &domain.CommentReport{},
+		Models: []mongo.IndexModel{
+			// a user has one open report per comment, and may report it
+			// again once it is dismissed
+			{
+				Keys: bson.D{{Key: "comment_id", Value: 1}, {Key: "user_id", Value: 1}},
+				Options: options.Index().SetUnique(true).SetName("comment_id_user_id_open_unique").
+					SetPartialFilterExpression(bson.M{"dismissed_at": bson.M{"$type": "null"}}),
+			},
+		},
+	},
+	{
+		Model: &domain.ModerationAction{},
+		Models: []mongo.IndexModel{
+			{Keys: bson.D{{Key: "comment_id", Value: 1}, {Key: "created_at", Value: -1}}},
+		},
+	},
+	{
+		Model: &domain.RefreshToken{},
+		Models: []mongo.IndexModel{
+			{Keys: bson.D{{Key: "token_hash", Value: 1}}, Options: options.Index().SetUnique(true)},
+			{Keys: bson.D{{Key: "family_id", Value: 1}}},
+			// expired tokens are removed by mongodb
+			{Keys: bson.D{{Key: "expires_at", Value: 1}}, Options: options.Index().SetExpireAfterSeconds(0)},
+		},
+	},
+	{
+		Model: &domain.EmailVerification{},
+		Models: []mongo.IndexModel{
+			{Keys: bson.D{{Key: "token_hash", Value: 1}}, Options: options.Index().SetUnique(true)},
+			// expired tokens are removed by mongodb
+			{Keys: bson.D{{Key: "expires_at", Value: 1}}, Options: options.Index().SetExpireAfterSeconds(0)},
+		},
+	},
+	{
+		Model: &domain.RevokedToken{},
+		Models: []mongo.IndexModel{
+			{Keys: bson.D{{Key: "jti", Value: 1}}, Options: options.Index().SetUnique(true)},
+			// revoked tokens are removed once they expire anyway
+			{Keys: bson.D{{Key: "expires_at", Value: 1}}, Options: options.Index().SetExpireAfterSeconds(0)},
+		},
+	},
+	{
+		Model: &domain.RevokedUser{},
+		Models: []mongo.IndexModel{
+			{Keys: bson.D{{Key: "user_id", Value: 1}}, Options: options.Index().SetUnique(true)},
+		},
+	},
+	{
+		Model: &domain.Reaction{},
+		Models: []mongo.IndexModel{
+			{Keys: bson.D{{Key: "comment_id", Value: 1}, {Key: "user_id", Value: 1}, {Key: "type", Value: 1}}, Options: options.Index().SetUnique(true)},
+			{Keys: bson.D{{Key: "comment_id", Value: 1}, {Key: "type", Value: 1}, {Key: "created_at", Value: -1}}},
+		},
+	},
+	{
+		Model: &domain.CommentRevision{},
+		Models: []mongo.IndexModel{
+			{Keys: bson.D{{Key: "comment_id", Value: 1}, {Key: "revision", Value: 1}}, Options: options.Index().SetUnique(true)},
+		},
+	},
+	{
+		Model: &domain.StarwarsDataHash{},
+		Models: []mongo.IndexModel{
+			{
+				Keys:    bson.D{{Key: "source", Value: 1}, {Key: "page_url", Value: 1}},
+				Options: options.Index().SetUnique(true).SetName("source_page_url_unique"),
+			},
+		},
+	},
+}
+
+func ensureIndexes(ctx context.Context) error {
+	for _, index := range replacedIndexes {
+		_, err := mgm.Coll(index.Model).Indexes().DropOne(ctx, index.Name)
+		if err != nil && !isMissingIndex(err) {
+			return err
+		}
+	}
+
+	for _, index := range indexes {
+		if _, err := mgm.Coll(index.Model).Indexes().CreateMany(ctx, index.Models); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// isMissingIndex reports whether err is mongodb refusing to drop an index,
+// or the collection of an index, that does not exist.
+func isMissingIndex(err error) bool {
+	var cmdErr mongo.CommandError
+	if !errors.As(err, &cmdErr) {
+		return false
+	}
+	return cmdErr.Code == 26 || cmdErr.Code == 27
+}
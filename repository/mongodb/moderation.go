@@ -0,0 +1,117 @@
+package mongodb
+
+import (
+	"context"
+	mongopagination "github.com/gobeam/mongo-go-pagination"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"movies-review-api/domain"
+	"time"
+
+	"github.com/Kamva/mgm/v2"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.uber.org/zap"
+)
+
+type mongoCommentReportRepository struct {
+	Logger *zap.Logger
+	Coll   *mgm.Collection
+}
+
+func (m *mongoCommentReportRepository) Create(ctx context.Context, report *domain.CommentReport) (*domain.CommentReport, error) {
+
+	err := m.Coll.CreateWithCtx(ctx, report)
+
+	// a user reporting a comment twice keeps the first open report
+	if mongo.IsDuplicateKeyError(err) {
+		var existing domain.CommentReport
+		err = m.Coll.FirstWithCtx(ctx, bson.M{"comment_id": report.CommentId, "user_id": report.UserId, "dismissed_at": nil}, &existing)
+		if err != nil {
+			return nil, err
+		}
+		return &existing, nil
+	}
+
+	if err != nil {
+		m.Logger.Error(err.Error(), zap.Error(err))
+		return nil, err
+	}
+
+	return report, nil
+}
+
+func (m *mongoCommentReportRepository) DismissAll(ctx context.Context, commentId string) error {
+	now := time.Now().UTC()
+
+	_, err := m.Coll.UpdateMany(ctx,
+		bson.M{"comment_id": commentId, "dismissed_at": nil},
+		bson.M{"$set": bson.M{"dismissed_at": now, "updated_at": now}})
+
+	if err != nil {
+		m.Logger.Error(err.Error(), zap.Error(err))
+		return err
+	}
+
+	id, err := primitive.ObjectIDFromHex(commentId)
+	if err != nil {
+		return err
+	}
+
+	_, err = mgm.Coll(&domain.Comment{}).UpdateByID(ctx, id, bson.M{"$set": bson.M{"report_count": 0}})
+
+	return err
+}
+
+func NewCommentReportRepository(logger *zap.Logger) domain.CommentReportRepository {
+	return &mongoCommentReportRepository{
+		Logger: logger,
+		Coll:   mgm.Coll(&domain.CommentReport{}),
+	}
+}
+
+type mongoModerationActionRepository struct {
+	Logger *zap.Logger
+	Coll   *mgm.Collection
+}
+
+func (m *mongoModerationActionRepository) Create(ctx context.Context, action *domain.ModerationAction) (*domain.ModerationAction, error) {
+
+	err := m.Coll.CreateWithCtx(ctx, action)
+
+	if err != nil {
+		m.Logger.Error(err.Error(), zap.Error(err))
+		return nil, err
+	}
+
+	return action, nil
+}
+
+func (m *mongoModerationActionRepository) FetchPaginatedCommentActions(ctx context.Context, commentId string, page, limit int64) (*domain.PaginatedModerationAction, error) {
+
+	var actions []domain.ModerationAction
+
+	paginatedData, err := mongopagination.New(m.Coll.Collection).
+		Context(ctx).
+		Limit(limit).
+		Page(page).
+		Sort("created_at", -1).
+		Filter(bson.M{"comment_id": commentId}).
+		Decode(&actions).
+		Find()
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &domain.PaginatedModerationAction{
+		Data:       actions,
+		Pagination: paginatedData,
+	}, nil
+}
+
+func NewModerationActionRepository(logger *zap.Logger) domain.ModerationActionRepository {
+	return &mongoModerationActionRepository{
+		Logger: logger,
+		Coll:   mgm.Coll(&domain.ModerationAction{}),
+	}
+}
@@ -1,18 +1,37 @@
 package mongodb
 
 import (
+	"context"
 	"github.com/Kamva/mgm/v2"
 	"movies-review-api/domain"
 	"os"
+	"time"
 
 	"go.mongodb.org/mongo-driver/mongo/options"
 	"go.uber.org/zap"
 )
 
 type MongoRepository struct {
-	UserRepo    domain.UserRepository
-	FilmRepo    domain.FilmRepository
-	CommentRepo domain.CommentRepository
+	UserRepo      domain.UserRepository
+	FilmRepo      domain.FilmRepository
+	CommentRepo   domain.CommentRepository
+	SyncRunRepo   domain.SyncRunRepository
+	CharacterRepo domain.CharacterRepository
+	PlanetRepo    domain.PlanetRepository
+	StarshipRepo  domain.StarshipRepository
+	VehicleRepo   domain.VehicleRepository
+	SpeciesRepo   domain.SpeciesRepository
+
+	CommentRevisionRepo   domain.CommentRevisionRepository
+	ReactionRepo          domain.ReactionRepository
+	RatingRepo            domain.RatingRepository
+	ReviewRepo            domain.ReviewRepository
+	ReviewVoteRepo        domain.ReviewVoteRepository
+	CommentReportRepo     domain.CommentReportRepository
+	ModerationRepo        domain.ModerationActionRepository
+	RefreshTokenRepo      domain.RefreshTokenRepository
+	RevocationStore       domain.RevocationStore
+	EmailVerificationRepo domain.EmailVerificationRepository
 }
 
 func New(l *zap.Logger) *MongoRepository {
@@ -33,9 +52,33 @@ func New(l *zap.Logger) *MongoRepository {
 		l.Error(err.Error(), zap.Error(err))
 	}
 
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	if err = ensureIndexes(ctx); err != nil {
+		l.Error(err.Error(), zap.Error(err))
+	}
+
 	return &MongoRepository{
-		UserRepo:    NewUserRepository(l),
-		FilmRepo:    NewFilmRepository(l),
-		CommentRepo: NewCommentRepository(l),
+		UserRepo:      NewUserRepository(l),
+		FilmRepo:      NewFilmRepository(l),
+		CommentRepo:   NewCommentRepository(l),
+		SyncRunRepo:   NewSyncRunRepository(l),
+		CharacterRepo: NewCharacterRepository(l),
+		PlanetRepo:    NewPlanetRepository(l),
+		StarshipRepo:  NewStarshipRepository(l),
+		VehicleRepo:   NewVehicleRepository(l),
+		SpeciesRepo:   NewSpeciesRepository(l),
+
+		CommentRevisionRepo:   NewCommentRevisionRepository(l),
+		ReactionRepo:          NewReactionRepository(l),
+		RatingRepo:            NewRatingRepository(l),
+		ReviewRepo:            NewReviewRepository(l),
+		ReviewVoteRepo:        NewReviewVoteRepository(l),
+		CommentReportRepo:     NewCommentReportRepository(l),
+		ModerationRepo:        NewModerationActionRepository(l),
+		RefreshTokenRepo:      NewRefreshTokenRepository(l),
+		RevocationStore:       NewRevocationStore(l),
+		EmailVerificationRepo: NewEmailVerificationRepository(l),
 	}
 }
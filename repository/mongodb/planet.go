@@ -0,0 +1,72 @@
+package mongodb
+
+import (
+	"context"
+	"errors"
+	mongopagination "github.com/gobeam/mongo-go-pagination"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"movies-review-api/domain"
+
+	"github.com/Kamva/mgm/v2"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.uber.org/zap"
+)
+
+type mongoPlanetRepository struct {
+	Logger *zap.Logger
+	Coll   *mgm.Collection
+}
+
+func (m *mongoPlanetRepository) FetchPaginatedPlanets(ctx context.Context, page, limit int64) (*domain.PaginatedPlanet, error) {
+
+	var planets []domain.Planet
+
+	filter := bson.D{}
+
+	paginatedData, err := mongopagination.New(m.Coll.Collection).
+		Context(ctx).
+		Limit(limit).
+		Page(page).
+		Sort("name", 1).
+		Filter(filter).
+		Decode(&planets).
+		Find()
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &domain.PaginatedPlanet{
+		Data:       planets,
+		Pagination: paginatedData,
+	}, nil
+}
+
+func (m *mongoPlanetRepository) GetById(ctx context.Context, id string) (*domain.Planet, error) {
+	var planet domain.Planet
+
+	primitiveId, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, errors.New("invalid resource id")
+	}
+
+	err = m.Coll.FindByIDWithCtx(ctx, primitiveId, &planet)
+
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, errors.New("resource not found")
+		}
+		m.Logger.Error(err.Error(), zap.Error(err))
+		return nil, err
+	}
+
+	return &planet, nil
+}
+
+func NewPlanetRepository(logger *zap.Logger) domain.PlanetRepository {
+	return &mongoPlanetRepository{
+		Logger: logger,
+		Coll:   mgm.Coll(&domain.Planet{}),
+	}
+}
@@ -0,0 +1,129 @@
+package mongodb
+
+import (
+	"context"
+	"errors"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"movies-review-api/domain"
+
+	"github.com/Kamva/mgm/v2"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.uber.org/zap"
+)
+
+type mongoRatingRepository struct {
+	Logger *zap.Logger
+	Coll   *mgm.Collection
+}
+
+func (m *mongoRatingRepository) Upsert(ctx context.Context, rating *domain.Rating) (*domain.Rating, error) {
+
+	err := withTransaction(ctx, func(sc mongo.SessionContext) error {
+		var existing domain.Rating
+
+		err := m.Coll.FirstWithCtx(sc, bson.M{"film_id": rating.FilmId, "user_id": rating.UserId}, &existing)
+
+		if err == mongo.ErrNoDocuments {
+			if err = m.Coll.CreateWithCtx(sc, rating); err != nil {
+				return err
+			}
+			return updateFilmRating(sc, rating.FilmId, rating.Score, 1)
+		}
+
+		if err != nil {
+			return err
+		}
+
+		scoreDelta := rating.Score - existing.Score
+		existing.Score = rating.Score
+		if err = m.Coll.UpdateWithCtx(sc, &existing); err != nil {
+			return err
+		}
+		*rating = existing
+
+		return updateFilmRating(sc, rating.FilmId, scoreDelta, 0)
+	})
+
+	if err != nil {
+		m.Logger.Error(err.Error(), zap.Error(err))
+		return nil, err
+	}
+
+	return rating, nil
+}
+
+func (m *mongoRatingRepository) Delete(ctx context.Context, filmId, userId string) error {
+
+	err := withTransaction(ctx, func(sc mongo.SessionContext) error {
+		var rating domain.Rating
+
+		err := m.Coll.FirstWithCtx(sc, bson.M{"film_id": filmId, "user_id": userId}, &rating)
+		if err != nil {
+			return err
+		}
+
+		if err = m.Coll.DeleteWithCtx(sc, &rating); err != nil {
+			return err
+		}
+
+		return updateFilmRating(sc, filmId, -rating.Score, -1)
+	})
+
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return errors.New("resource not found")
+		}
+		m.Logger.Error(err.Error(), zap.Error(err))
+		return err
+	}
+
+	return nil
+}
+
+func (m *mongoRatingRepository) GetByFilmAndUser(ctx context.Context, filmId, userId string) (*domain.Rating, error) {
+	var rating domain.Rating
+
+	err := m.Coll.FirstWithCtx(ctx, bson.M{"film_id": filmId, "user_id": userId}, &rating)
+
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, errors.New("resource not found")
+		}
+		return nil, err
+	}
+
+	return &rating, nil
+}
+
+// updateFilmRating applies a change of the rating sum and count to the film
+// and recomputes its average from them.
+func updateFilmRating(ctx context.Context, filmId string, scoreDelta, countDelta int64) error {
+	id, err := primitive.ObjectIDFromHex(filmId)
+	if err != nil {
+		return err
+	}
+
+	_, err = mgm.Coll(&domain.Film{}).UpdateByID(ctx, id, mongo.Pipeline{
+		{{Key: "$set", Value: bson.M{
+			"rating_sum":   bson.M{"$add": bson.A{bson.M{"$ifNull": bson.A{"$rating_sum", 0}}, scoreDelta}},
+			"rating_count": bson.M{"$add": bson.A{bson.M{"$ifNull": bson.A{"$rating_count", 0}}, countDelta}},
+		}}},
+		{{Key: "$set", Value: bson.M{
+			"rating_avg": bson.M{"$cond": bson.A{
+				bson.M{"$gt": bson.A{"$rating_count", 0}},
+				bson.M{"$round": bson.A{bson.M{"$divide": bson.A{"$rating_sum", "$rating_count"}}, 2}},
+				0,
+			}},
+		}}},
+	})
+
+	return err
+}
+
+func NewRatingRepository(logger *zap.Logger) domain.RatingRepository {
+	return &mongoRatingRepository{
+		Logger: logger,
+		Coll:   mgm.Coll(&domain.Rating{}),
+	}
+}
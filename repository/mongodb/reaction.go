@@ -0,0 +1,103 @@
+package mongodb
+
+import (
+	"context"
+	"errors"
+	mongopagination "github.com/gobeam/mongo-go-pagination"
+	"go.mongodb.org/mongo-driver/mongo"
+	"movies-review-api/domain"
+
+	"github.com/Kamva/mgm/v2"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.uber.org/zap"
+)
+
+type mongoReactionRepository struct {
+	Logger *zap.Logger
+	Coll   *mgm.Collection
+}
+
+func (m *mongoReactionRepository) Create(ctx context.Context, reaction *domain.Reaction) (*domain.Reaction, error) {
+
+	err := m.Coll.CreateWithCtx(ctx, reaction)
+
+	// reacting twice with the same type keeps the first reaction
+	if mongo.IsDuplicateKeyError(err) {
+		var existing domain.Reaction
+		err = m.Coll.FirstWithCtx(ctx, bson.M{
+			"comment_id": reaction.CommentId,
+			"user_id":    reaction.UserId,
+			"type":       reaction.Type,
+		}, &existing)
+		if err != nil {
+			return nil, err
+		}
+		return &existing, nil
+	}
+
+	if err != nil {
+		m.Logger.Error(err.Error(), zap.Error(err))
+		return nil, err
+	}
+
+	return reaction, nil
+}
+
+func (m *mongoReactionRepository) Delete(ctx context.Context, commentId, userId, reactionType string) error {
+	var reaction domain.Reaction
+
+	err := m.Coll.FirstWithCtx(ctx, bson.M{
+		"comment_id": commentId,
+		"user_id":    userId,
+		"type":       reactionType,
+	}, &reaction)
+
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return errors.New("resource not found")
+		}
+		return err
+	}
+
+	if err = m.Coll.DeleteWithCtx(ctx, &reaction); err != nil {
+		m.Logger.Error(err.Error(), zap.Error(err))
+		return err
+	}
+
+	return nil
+}
+
+func (m *mongoReactionRepository) FetchPaginatedCommentReactions(ctx context.Context, commentId, reactionType string, page, limit int64) (*domain.PaginatedReaction, error) {
+
+	var reactions []domain.Reaction
+
+	filter := bson.M{"comment_id": commentId}
+	if reactionType != "" {
+		filter["type"] = reactionType
+	}
+
+	paginatedData, err := mongopagination.New(m.Coll.Collection).
+		Context(ctx).
+		Limit(limit).
+		Page(page).
+		Sort("created_at", -1).
+		Filter(filter).
+		Decode(&reactions).
+		Find()
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &domain.PaginatedReaction{
+		Data:       reactions,
+		Pagination: paginatedData,
+	}, nil
+}
+
+func NewReactionRepository(logger *zap.Logger) domain.ReactionRepository {
+	return &mongoReactionRepository{
+		Logger: logger,
+		Coll:   mgm.Coll(&domain.Reaction{}),
+	}
+}
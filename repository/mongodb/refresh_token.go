@@ -0,0 +1,99 @@
+package mongodb
+
+import (
+	"context"
+	"errors"
+	"go.mongodb.org/mongo-driver/mongo"
+	"movies-review-api/domain"
+	"time"
+
+	"github.com/Kamva/mgm/v2"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.uber.org/zap"
+)
+
+type mongoRefreshTokenRepository struct {
+	Logger *zap.Logger
+	Coll   *mgm.Collection
+}
+
+func (m *mongoRefreshTokenRepository) Create(ctx context.Context, token *domain.RefreshToken) (*domain.RefreshToken, error) {
+
+	err := m.Coll.CreateWithCtx(ctx, token)
+
+	if err != nil {
+		m.Logger.Error(err.Error(), zap.Error(err))
+		return nil, err
+	}
+
+	return token, nil
+}
+
+func (m *mongoRefreshTokenRepository) GetByHash(ctx context.Context, tokenHash string) (*domain.RefreshToken, error) {
+	var token domain.RefreshToken
+
+	err := m.Coll.FirstWithCtx(ctx, bson.M{"token_hash": tokenHash}, &token)
+
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, errors.New("resource not found")
+		}
+		m.Logger.Error(err.Error(), zap.Error(err))
+		return nil, err
+	}
+
+	return &token, nil
+}
+
+func (m *mongoRefreshTokenRepository) MarkUsed(ctx context.Context, token *domain.RefreshToken) (bool, error) {
+	now := time.Now().UTC()
+
+	result, err := m.Coll.UpdateOne(ctx,
+		bson.M{"_id": token.ID, "used_at": nil},
+		bson.M{"$set": bson.M{"used_at": now, "updated_at": now}})
+
+	if err != nil {
+		m.Logger.Error(err.Error(), zap.Error(err))
+		return false, err
+	}
+
+	token.UsedAt = &now
+	return result.ModifiedCount > 0, nil
+}
+
+func (m *mongoRefreshTokenRepository) RevokeFamily(ctx context.Context, familyId string) error {
+	now := time.Now().UTC()
+
+	_, err := m.Coll.UpdateMany(ctx,
+		bson.M{"family_id": familyId, "revoked_at": nil},
+		bson.M{"$set": bson.M{"revoked_at": now, "updated_at": now}})
+
+	if err != nil {
+		m.Logger.Error(err.Error(), zap.Error(err))
+		return err
+	}
+
+	return nil
+}
+
+func (m *mongoRefreshTokenRepository) RevokeUser(ctx context.Context, userId string) error {
+	now := time.Now().UTC()
+
+	_, err := m.Coll.UpdateMany(ctx,
+		bson.M{"user_id": userId, "revoked_at": nil},
+		bson.M{"$set": bson.M{"revoked_at": now, "updated_at": now}})
+
+	if err != nil {
+		m.Logger.Error(err.Error(), zap.Error(err))
+		return err
+	}
+
+	return nil
+}
+
+func NewRefreshTokenRepository(logger *zap.Logger) domain.RefreshTokenRepository {
+	return &mongoRefreshTokenRepository{
+		Logger: logger,
+		Coll:   mgm.Coll(&domain.RefreshToken{}),
+	}
+}
@@ -0,0 +1,189 @@
+package mongodb
+
+import (
+	"context"
+	"errors"
+	mongopagination "github.com/gobeam/mongo-go-pagination"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"movies-review-api/domain"
+
+	"github.com/Kamva/mgm/v2"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.uber.org/zap"
+)
+
+type mongoReviewRepository struct {
+	Logger *zap.Logger
+	Coll   *mgm.Collection
+}
+
+func (m *mongoReviewRepository) Create(ctx context.Context, review *domain.Review) (*domain.Review, error) {
+
+	err := m.Coll.CreateWithCtx(ctx, review)
+
+	if mongo.IsDuplicateKeyError(err) {
+		return nil, domain.ErrReviewExists
+	}
+
+	if err != nil {
+		m.Logger.Error(err.Error(), zap.Error(err))
+		return nil, err
+	}
+
+	return review, nil
+}
+
+func (m *mongoReviewRepository) GetById(ctx context.Context, id string) (*domain.Review, error) {
+	var review domain.Review
+
+	primitiveId, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, errors.New("invalid resource id")
+	}
+
+	err = m.Coll.FirstWithCtx(ctx, bson.M{"_id": primitiveId}, &review)
+
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, errors.New("resource not found")
+		}
+		m.Logger.Error(err.Error(), zap.Error(err))
+		return nil, err
+	}
+
+	return &review, nil
+}
+
+func (m *mongoReviewRepository) Update(ctx context.Context, review *domain.Review) (*domain.Review, error) {
+
+	// only the review content is set so concurrent helpful votes are kept
+	_, err := m.Coll.UpdateByID(ctx, review.ID, bson.M{"$set": bson.M{
+		"title":      review.Title,
+		"body":       review.Body,
+		"rating":     review.Rating,
+		"spoiler":    review.Spoiler,
+		"updated_at": review.UpdatedAt,
+	}})
+
+	if err != nil {
+		m.Logger.Error(err.Error(), zap.Error(err))
+		return nil, err
+	}
+
+	return review, nil
+}
+
+func (m *mongoReviewRepository) Delete(ctx context.Context, review *domain.Review) error {
+
+	err := m.Coll.DeleteWithCtx(ctx, review)
+
+	if err != nil {
+		m.Logger.Error(err.Error(), zap.Error(err))
+		return err
+	}
+
+	return nil
+}
+
+func (m *mongoReviewRepository) FetchPaginatedFilmReviews(ctx context.Context, filmId, sort string, page, limit int64) (*domain.PaginatedReview, error) {
+
+	var reviews []domain.Review
+
+	query := mongopagination.New(m.Coll.Collection).
+		Context(ctx).
+		Limit(limit).
+		Page(page)
+
+	if sort == domain.ReviewSortHelpful {
+		query = query.Sort("helpful_count", -1)
+	}
+
+	paginatedData, err := query.
+		Sort("created_at", -1).
+		Filter(bson.M{"film_id": filmId}).
+		Decode(&reviews).
+		Find()
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &domain.PaginatedReview{
+		Data:       reviews,
+		Pagination: paginatedData,
+	}, nil
+}
+
+func NewReviewRepository(logger *zap.Logger) domain.ReviewRepository {
+	return &mongoReviewRepository{
+		Logger: logger,
+		Coll:   mgm.Coll(&domain.Review{}),
+	}
+}
+
+type mongoReviewVoteRepository struct {
+	Logger *zap.Logger
+	Coll   *mgm.Collection
+}
+
+func (m *mongoReviewVoteRepository) Create(ctx context.Context, vote *domain.ReviewVote) (*domain.ReviewVote, error) {
+
+	err := m.Coll.CreateWithCtx(ctx, vote)
+
+	// voting twice keeps the first vote
+	if mongo.IsDuplicateKeyError(err) {
+		var existing domain.ReviewVote
+		err = m.Coll.FirstWithCtx(ctx, bson.M{"review_id": vote.ReviewId, "user_id": vote.UserId}, &existing)
+		if err != nil {
+			return nil, err
+		}
+		return &existing, nil
+	}
+
+	if err != nil {
+		m.Logger.Error(err.Error(), zap.Error(err))
+		return nil, err
+	}
+
+	return vote, nil
+}
+
+func (m *mongoReviewVoteRepository) Delete(ctx context.Context, reviewId, userId string) error {
+	var vote domain.ReviewVote
+
+	err := m.Coll.FirstWithCtx(ctx, bson.M{"review_id": reviewId, "user_id": userId}, &vote)
+
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return errors.New("resource not found")
+		}
+		return err
+	}
+
+	if err = m.Coll.DeleteWithCtx(ctx, &vote); err != nil {
+		m.Logger.Error(err.Error(), zap.Error(err))
+		return err
+	}
+
+	return nil
+}
+
+func (m *mongoReviewVoteRepository) DeleteByReview(ctx context.Context, reviewId string) error {
+
+	_, err := m.Coll.DeleteMany(ctx, bson.M{"review_id": reviewId})
+
+	if err != nil {
+		m.Logger.Error(err.Error(), zap.Error(err))
+		return err
+	}
+
+	return nil
+}
+
+func NewReviewVoteRepository(logger *zap.Logger) domain.ReviewVoteRepository {
+	return &mongoReviewVoteRepository{
+		Logger: logger,
+		Coll:   mgm.Coll(&domain.ReviewVote{}),
+	}
+}
@@ -0,0 +1,84 @@
+package mongodb
+
+import (
+	"context"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"movies-review-api/domain"
+	"time"
+
+	"github.com/Kamva/mgm/v2"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.uber.org/zap"
+)
+
+type mongoRevocationStore struct {
+	Logger    *zap.Logger
+	TokenColl *mgm.Collection
+	UserColl  *mgm.Collection
+}
+
+func (m *mongoRevocationStore) RevokeToken(ctx context.Context, jti string, expiresAt time.Time) error {
+
+	err := m.TokenColl.CreateWithCtx(ctx, &domain.RevokedToken{Jti: jti, ExpiresAt: expiresAt})
+
+	// revoking a token twice is fine
+	if err != nil && !mongo.IsDuplicateKeyError(err) {
+		m.Logger.Error(err.Error(), zap.Error(err))
+		return err
+	}
+
+	return nil
+}
+
+func (m *mongoRevocationStore) RevokeUser(ctx context.Context, userId string) error {
+	now := time.Now().UTC()
+
+	_, err := m.UserColl.UpdateOne(ctx,
+		bson.M{"user_id": userId},
+		bson.M{
+			"$set":         bson.M{"revoked_at": now, "updated_at": now},
+			"$setOnInsert": bson.M{"created_at": now},
+		},
+		options.Update().SetUpsert(true))
+
+	if err != nil {
+		m.Logger.Error(err.Error(), zap.Error(err))
+		return err
+	}
+
+	return nil
+}
+
+func (m *mongoRevocationStore) IsRevoked(ctx context.Context, jti, userId string, issuedAt time.Time) (bool, error) {
+	if jti != "" {
+		count, err := m.TokenColl.CountDocuments(ctx, bson.M{"jti": jti}, options.Count().SetLimit(1))
+		if err != nil {
+			return false, err
+		}
+		if count > 0 {
+			return true, nil
+		}
+	}
+
+	var revoked domain.RevokedUser
+
+	err := m.UserColl.FirstWithCtx(ctx, bson.M{"user_id": userId}, &revoked)
+
+	if err == mongo.ErrNoDocuments {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	return domain.IssuedUpTo(issuedAt, revoked.RevokedAt), nil
+}
+
+func NewRevocationStore(logger *zap.Logger) domain.RevocationStore {
+	return &mongoRevocationStore{
+		Logger:    logger,
+		TokenColl: mgm.Coll(&domain.RevokedToken{}),
+		UserColl:  mgm.Coll(&domain.RevokedUser{}),
+	}
+}
@@ -0,0 +1,72 @@
+package mongodb
+
+import (
+	"context"
+	"errors"
+	mongopagination "github.com/gobeam/mongo-go-pagination"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"movies-review-api/domain"
+
+	"github.com/Kamva/mgm/v2"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.uber.org/zap"
+)
+
+type mongoSpeciesRepository struct {
+	Logger *zap.Logger
+	Coll   *mgm.Collection
+}
+
+func (m *mongoSpeciesRepository) FetchPaginatedSpecies(ctx context.Context, page, limit int64) (*domain.PaginatedSpecies, error) {
+
+	var speciesList []domain.Species
+
+	filter := bson.D{}
+
+	paginatedData, err := mongopagination.New(m.Coll.Collection).
+		Context(ctx).
+		Limit(limit).
+		Page(page).
+		Sort("name", 1).
+		Filter(filter).
+		Decode(&speciesList).
+		Find()
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &domain.PaginatedSpecies{
+		Data:       speciesList,
+		Pagination: paginatedData,
+	}, nil
+}
+
+func (m *mongoSpeciesRepository) GetById(ctx context.Context, id string) (*domain.Species, error) {
+	var species domain.Species
+
+	primitiveId, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, errors.New("invalid resource id")
+	}
+
+	err = m.Coll.FindByIDWithCtx(ctx, primitiveId, &species)
+
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, errors.New("resource not found")
+		}
+		m.Logger.Error(err.Error(), zap.Error(err))
+		return nil, err
+	}
+
+	return &species, nil
+}
+
+func NewSpeciesRepository(logger *zap.Logger) domain.SpeciesRepository {
+	return &mongoSpeciesRepository{
+		Logger: logger,
+		Coll:   mgm.Coll(&domain.Species{}),
+	}
+}
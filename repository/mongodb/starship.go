@@ -0,0 +1,72 @@
+package mongodb
+
+import (
+	"context"
+	"errors"
+	mongopagination "github.com/gobeam/mongo-go-pagination"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"movies-review-api/domain"
+
+	"github.com/Kamva/mgm/v2"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.uber.org/zap"
+)
+
+type mongoStarshipRepository struct {
+	Logger *zap.Logger
+	Coll   *mgm.Collection
+}
+
+func (m *mongoStarshipRepository) FetchPaginatedStarships(ctx context.Context, page, limit int64) (*domain.PaginatedStarship, error) {
+
+	var starships []domain.Starship
+
+	filter := bson.D{}
+
+	paginatedData, err := mongopagination.New(m.Coll.Collection).
+		Context(ctx).
+		Limit(limit).
+		Page(page).
+		Sort("name", 1).
+		Filter(filter).
+		Decode(&starships).
+		Find()
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &domain.PaginatedStarship{
+		Data:       starships,
+		Pagination: paginatedData,
+	}, nil
+}
+
+func (m *mongoStarshipRepository) GetById(ctx context.Context, id string) (*domain.Starship, error) {
+	var starship domain.Starship
+
+	primitiveId, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, errors.New("invalid resource id")
+	}
+
+	err = m.Coll.FindByIDWithCtx(ctx, primitiveId, &starship)
+
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, errors.New("resource not found")
+		}
+		m.Logger.Error(err.Error(), zap.Error(err))
+		return nil, err
+	}
+
+	return &starship, nil
+}
+
+func NewStarshipRepository(logger *zap.Logger) domain.StarshipRepository {
+	return &mongoStarshipRepository{
+		Logger: logger,
+		Coll:   mgm.Coll(&domain.Starship{}),
+	}
+}
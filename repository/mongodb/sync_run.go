@@ -0,0 +1,58 @@
+package mongodb
+
+import (
+	"context"
+	mongopagination "github.com/gobeam/mongo-go-pagination"
+	"movies-review-api/domain"
+
+	"github.com/Kamva/mgm/v2"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.uber.org/zap"
+)
+
+type mongoSyncRunRepository struct {
+	Logger *zap.Logger
+	Coll   *mgm.Collection
+}
+
+func (m *mongoSyncRunRepository) Create(ctx context.Context, run *domain.SyncRun) (*domain.SyncRun, error) {
+
+	err := m.Coll.CreateWithCtx(ctx, run)
+
+	if err != nil {
+		m.Logger.Error(err.Error(), zap.Error(err))
+		return nil, err
+	}
+
+	return run, nil
+}
+
+func (m *mongoSyncRunRepository) FetchPaginatedSyncRuns(ctx context.Context, page, limit int64) (*domain.PaginatedSyncRun, error) {
+
+	var runs []domain.SyncRun
+
+	paginatedData, err := mongopagination.New(m.Coll.Collection).
+		Context(ctx).
+		Limit(limit).
+		Page(page).
+		Sort("started_at", -1).
+		Filter(bson.D{}).
+		Decode(&runs).
+		Find()
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &domain.PaginatedSyncRun{
+		Data:       runs,
+		Pagination: paginatedData,
+	}, nil
+}
+
+func NewSyncRunRepository(logger *zap.Logger) domain.SyncRunRepository {
+	return &mongoSyncRunRepository{
+		Logger: logger,
+		Coll:   mgm.Coll(&domain.SyncRun{}),
+	}
+}
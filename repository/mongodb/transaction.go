@@ -0,0 +1,20 @@
+package mongodb
+
+import (
+	"context"
+	"github.com/Kamva/mgm/v2"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// withTransaction runs fn in a transaction, committing it when fn succeeds
+// and aborting it otherwise.
+func withTransaction(ctx context.Context, fn func(sc mongo.SessionContext) error) error {
+	return mgm.TransactionWithCtx(ctx, func(session mongo.Session, sc mongo.SessionContext) error {
+		if err := fn(sc); err != nil {
+			_ = session.AbortTransaction(sc)
+			return err
+		}
+
+		return session.CommitTransaction(sc)
+	})
+}
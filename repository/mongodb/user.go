@@ -3,12 +3,14 @@ package mongodb
 import (
 	"context"
 	"errors"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
 	"movies-review-api/domain"
+	"time"
 
 	"github.com/Kamva/mgm/v2"
 	"go.mongodb.org/mongo-driver/bson"
-	//"go.mongodb.org/mongo-driver/mongo/options"
+	"go.mongodb.org/mongo-driver/mongo/options"
 	"go.uber.org/zap"
 )
 
@@ -24,7 +26,7 @@ func (m mongoUserRepository) GetByEmail(ctx context.Context, email string) (*dom
 
 	if err != nil {
 		if err == mongo.ErrNoDocuments {
-			return nil, errors.New("user not found")
+			return nil, domain.ErrUserNotFound
 		}
 		return nil, err
 	}
@@ -56,6 +58,78 @@ func (m mongoUserRepository) GetById(ctx context.Context, id string) (*domain.Us
 
 	return &user, nil
 }
+func (m mongoUserRepository) Ban(ctx context.Context, userId string) error {
+	id, err := primitive.ObjectIDFromHex(userId)
+	if err != nil {
+		return errors.New("invalid resource id")
+	}
+
+	now := time.Now().UTC()
+
+	result, err := m.Coll.UpdateOne(ctx,
+		bson.M{"_id": id},
+		bson.M{"$set": bson.M{"banned_at": now, "updated_at": now}})
+
+	if err != nil {
+		m.Logger.Error(err.Error(), zap.Error(err))
+		return err
+	}
+
+	if result.MatchedCount == 0 {
+		return errors.New("user not found")
+	}
+
+	return nil
+}
+
+func (m mongoUserRepository) SetRole(ctx context.Context, userId, role string) (*domain.User, error) {
+	id, err := primitive.ObjectIDFromHex(userId)
+	if err != nil {
+		return nil, errors.New("invalid resource id")
+	}
+
+	var user domain.User
+
+	err = m.Coll.FindOneAndUpdate(ctx,
+		bson.M{"_id": id},
+		bson.M{"$set": bson.M{"role": role, "updated_at": time.Now().UTC()}},
+		options.FindOneAndUpdate().SetReturnDocument(options.After)).Decode(&user)
+
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, errors.New("user not found")
+		}
+		m.Logger.Error(err.Error(), zap.Error(err))
+		return nil, err
+	}
+
+	return &user, nil
+}
+
+func (m mongoUserRepository) MarkEmailVerified(ctx context.Context, userId string) error {
+	id, err := primitive.ObjectIDFromHex(userId)
+	if err != nil {
+		return errors.New("invalid resource id")
+	}
+
+	now := time.Now().UTC()
+
+	result, err := m.Coll.UpdateOne(ctx,
+		bson.M{"_id": id},
+		bson.M{"$set": bson.M{"email_verified_at": now, "updated_at": now}})
+
+	if err != nil {
+		m.Logger.Error(err.Error(), zap.Error(err))
+		return err
+	}
+
+	if result.MatchedCount == 0 {
+		return domain.ErrUserNotFound
+	}
+
+	return nil
+}
+
 func NewUserRepository(logger *zap.Logger) domain.UserRepository {
 	return &mongoUserRepository{
 		Logger: logger,
@@ -0,0 +1,72 @@
+package mongodb
+
+import (
+	"context"
+	"errors"
+	mongopagination "github.com/gobeam/mongo-go-pagination"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"movies-review-api/domain"
+
+	"github.com/Kamva/mgm/v2"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.uber.org/zap"
+)
+
+type mongoVehicleRepository struct {
+	Logger *zap.Logger
+	Coll   *mgm.Collection
+}
+
+func (m *mongoVehicleRepository) FetchPaginatedVehicles(ctx context.Context, page, limit int64) (*domain.PaginatedVehicle, error) {
+
+	var vehicles []domain.Vehicle
+
+	filter := bson.D{}
+
+	paginatedData, err := mongopagination.New(m.Coll.Collection).
+		Context(ctx).
+		Limit(limit).
+		Page(page).
+		Sort("name", 1).
+		Filter(filter).
+		Decode(&vehicles).
+		Find()
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &domain.PaginatedVehicle{
+		Data:       vehicles,
+		Pagination: paginatedData,
+	}, nil
+}
+
+func (m *mongoVehicleRepository) GetById(ctx context.Context, id string) (*domain.Vehicle, error) {
+	var vehicle domain.Vehicle
+
+	primitiveId, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, errors.New("invalid resource id")
+	}
+
+	err = m.Coll.FindByIDWithCtx(ctx, primitiveId, &vehicle)
+
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, errors.New("resource not found")
+		}
+		m.Logger.Error(err.Error(), zap.Error(err))
+		return nil, err
+	}
+
+	return &vehicle, nil
+}
+
+func NewVehicleRepository(logger *zap.Logger) domain.VehicleRepository {
+	return &mongoVehicleRepository{
+		Logger: logger,
+		Coll:   mgm.Coll(&domain.Vehicle{}),
+	}
+}
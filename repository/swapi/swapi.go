@@ -0,0 +1,194 @@
+package swapi
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"movies-review-api/domain"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const (
+	SourceName     = "swapi"
+	DefaultBaseUrl = "https://swapi.dev/api"
+)
+
+type apiResponse struct {
+	Next     string      `json:"next"`
+	Previous string      `json:"previous"`
+	Count    int64       `json:"count"`
+	Results  []swapiFilm `json:"results"`
+}
+
+type resourceResponse struct {
+	Next    string            `json:"next"`
+	Count   int64             `json:"count"`
+	Results []json.RawMessage `json:"results"`
+}
+
+// swapiMeta holds the fields every SWAPI resource shares.
+type swapiMeta struct {
+	Created time.Time `json:"created"`
+	Edited  time.Time `json:"edited"`
+	Url     string    `json:"url"`
+}
+
+// resourcePaths maps each resource kind to its SWAPI path.
+var resourcePaths = map[domain.ResourceKind]string{
+	domain.ResourceCharacters: "people",
+	domain.ResourcePlanets:    "planets",
+	domain.ResourceStarships:  "starships",
+	domain.ResourceVehicles:   "vehicles",
+	domain.ResourceSpecies:    "species",
+}
+
+type swapiFilm struct {
+	Title        string    `json:"title"`
+	EpisodeId    int64     `json:"episode_id"`
+	OpeningCrawl string    `json:"opening_crawl"`
+	Director     string    `json:"director"`
+	Producer     string    `json:"producer"`
+	ReleaseDate  string    `json:"release_date"`
+	Characters   []string  `json:"characters"`
+	Planets      []string  `json:"planets"`
+	Starships    []string  `json:"starships"`
+	Vehicles     []string  `json:"vehicles"`
+	Species      []string  `json:"species"`
+	Created      time.Time `json:"created"`
+	Edited       time.Time `json:"edited"`
+	Url          string    `json:"url"`
+}
+
+func (f swapiFilm) toFilm() domain.Film {
+	return domain.Film{
+		Title:           f.Title,
+		ReleaseDate:     f.ReleaseDate,
+		EpisodeId:       f.EpisodeId,
+		OpeningCrawl:    f.OpeningCrawl,
+		Director:        f.Director,
+		Producer:        f.Producer,
+		Characters:      f.Characters,
+		Planets:         f.Planets,
+		Starships:       f.Starships,
+		Vehicles:        f.Vehicles,
+		Species:         f.Species,
+		SourceCreatedAt: f.Created.UTC(),
+		SourceEditedAt:  f.Edited.UTC(),
+		Source:          SourceName,
+		ExternalId:      f.Url,
+	}
+}
+
+type swapiFilmSource struct {
+	BaseUrl string
+	Client  *http.Client
+}
+
+func (s *swapiFilmSource) Name() string {
+	return SourceName
+}
+
+func (s *swapiFilmSource) FetchPage(ctx context.Context, pageUrl string) (*domain.FilmSourcePage, error) {
+	if pageUrl == "" {
+		pageUrl = s.BaseUrl + "/films/"
+	}
+
+	var data apiResponse
+	if err := s.get(ctx, pageUrl, &data); err != nil {
+		return nil, err
+	}
+
+	films := make([]domain.Film, 0, len(data.Results))
+	for _, f := range data.Results {
+		films = append(films, f.toFilm())
+	}
+
+	return &domain.FilmSourcePage{
+		Url:     pageUrl,
+		Next:    data.Next,
+		Count:   data.Count,
+		Results: films,
+	}, nil
+}
+
+func (s *swapiFilmSource) FetchResourcePage(ctx context.Context, kind domain.ResourceKind, pageUrl string) (*domain.ResourcePage, error) {
+	path, ok := resourcePaths[kind]
+	if !ok {
+		return nil, fmt.Errorf("%s does not serve %s", SourceName, kind)
+	}
+
+	if pageUrl == "" {
+		pageUrl = s.BaseUrl + "/" + path + "/"
+	}
+
+	var data resourceResponse
+	if err := s.get(ctx, pageUrl, &data); err != nil {
+		return nil, err
+	}
+
+	resources := make([]domain.Resource, 0, len(data.Results))
+	for _, raw := range data.Results {
+		resource := domain.NewResource(kind)
+		if err := json.Unmarshal(raw, resource); err != nil {
+			return nil, err
+		}
+
+		var meta swapiMeta
+		if err := json.Unmarshal(raw, &meta); err != nil {
+			return nil, err
+		}
+
+		if character, ok := resource.(*domain.Character); ok {
+			character.HeightCm = domain.ParseHeightCm(character.Height)
+		}
+
+		sourceResource := resource.GetSourceResource()
+		sourceResource.Source = SourceName
+		sourceResource.ExternalId = meta.Url
+		sourceResource.SourceCreatedAt = meta.Created.UTC()
+		sourceResource.SourceEditedAt = meta.Edited.UTC()
+
+		resources = append(resources, resource)
+	}
+
+	return &domain.ResourcePage{
+		Url:     pageUrl,
+		Next:    data.Next,
+		Count:   data.Count,
+		Results: resources,
+	}, nil
+}
+
+func (s *swapiFilmSource) get(ctx context.Context, url string, v interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := s.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s returned status %d for %s", SourceName, resp.StatusCode, url)
+	}
+
+	return json.NewDecoder(resp.Body).Decode(v)
+}
+
+// NewFilmSource returns a SWAPI backed domain.CatalogSource. An empty baseUrl
+// falls back to the public swapi.dev api.
+func NewFilmSource(baseUrl string) domain.CatalogSource {
+	if baseUrl == "" {
+		baseUrl = DefaultBaseUrl
+	}
+
+	return &swapiFilmSource{
+		BaseUrl: strings.TrimSuffix(baseUrl, "/"),
+		Client:  &http.Client{Timeout: 30 * time.Second},
+	}
+}
@@ -0,0 +1,201 @@
+package swapi
+
+import (
+	"context"
+	"fmt"
+	"movies-review-api/domain"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Kamva/mgm/v2"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.uber.org/zap"
+)
+
+// fakeSwapi serves two pages of films and a page of people. The films of
+// the second page can be swapped between runs.
+type fakeSwapi struct {
+	*httptest.Server
+
+	mu         sync.Mutex
+	secondPage []string
+}
+
+func (f *fakeSwapi) setSecondPage(films ...string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.secondPage = films
+}
+
+func newFakeSwapi(t *testing.T) *fakeSwapi {
+	f := &fakeSwapi{secondPage: []string{filmJSON(3, "Revenge of the Sith")}}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/films/", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Query().Get("page") == "2" {
+			f.mu.Lock()
+			defer f.mu.Unlock()
+			fmt.Fprintf(w, `{"count": 3, "next": null, "results": [%s]}`, strings.Join(f.secondPage, ","))
+			return
+		}
+		fmt.Fprintf(w, `{"count": 3, "next": "%s/films/?page=2", "results": [%s, %s]}`,
+			f.URL, filmJSON(1, "The Phantom Menace"), filmJSON(2, "Attack of the Clones"))
+	})
+	mux.HandleFunc("/people/", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"count": 1, "next": null, "results": [{
+			"name": "Luke Skywalker", "height": "172",
+			"created": "2014-12-09T13:50:51.644000Z", "edited": "2014-12-20T21:17:56.891000Z",
+			"url": "%s/people/1/"}]}`, f.URL)
+	})
+
+	f.Server = httptest.NewServer(mux)
+	t.Cleanup(f.Close)
+	return f
+}
+
+func filmJSON(episode int, title string) string {
+	return fmt.Sprintf(`{
+		"title": %q, "episode_id": %d, "director": "George Lucas", "release_date": "1999-05-19",
+		"characters": ["https://swapi.dev/api/people/1/"],
+		"created": "2014-12-19T16:52:55.740000Z", "edited": "2014-12-20T10:52:14.024000Z",
+		"url": "https://swapi.dev/api/films/%d/"}`, title, episode, episode)
+}
+
+func TestFetchPage(t *testing.T) {
+	api := newFakeSwapi(t)
+	source := NewFilmSource(api.URL + "/")
+
+	page, err := source.FetchPage(context.Background(), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if page.Url != api.URL+"/films/" {
+		t.Errorf("page url = %q", page.Url)
+	}
+	if page.Next != api.URL+"/films/?page=2" {
+		t.Errorf("next = %q", page.Next)
+	}
+	if page.Count != 3 || len(page.Results) != 2 {
+		t.Fatalf("count = %d, results = %d", page.Count, len(page.Results))
+	}
+
+	film := page.Results[0]
+	if film.Title != "The Phantom Menace" || film.EpisodeId != 1 || film.Director != "George Lucas" {
+		t.Errorf("film = %+v", film)
+	}
+	if film.Source != SourceName || film.ExternalId != "https://swapi.dev/api/films/1/" {
+		t.Errorf("source = %q, external id = %q", film.Source, film.ExternalId)
+	}
+	if want := time.Date(2014, 12, 19, 16, 52, 55, 740000000, time.UTC); !film.SourceCreatedAt.Equal(want) {
+		t.Errorf("source created at = %v, want %v", film.SourceCreatedAt, want)
+	}
+
+	next, err := source.FetchPage(context.Background(), page.Next)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if next.Next != "" || len(next.Results) != 1 {
+		t.Errorf("next = %q, results = %d", next.Next, len(next.Results))
+	}
+}
+
+func TestFetchPageStatus(t *testing.T) {
+	srv := httptest.NewServer(http.NotFoundHandler())
+	defer srv.Close()
+
+	if _, err := NewFilmSource(srv.URL).FetchPage(context.Background(), ""); err == nil {
+		t.Fatal("expected an error for a 404")
+	}
+}
+
+func TestFetchResourcePage(t *testing.T) {
+	api := newFakeSwapi(t)
+	source := NewFilmSource(api.URL)
+
+	page, err := source.FetchResourcePage(context.Background(), domain.ResourceCharacters, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(page.Results) != 1 {
+		t.Fatalf("results = %d", len(page.Results))
+	}
+
+	character, ok := page.Results[0].(*domain.Character)
+	if !ok {
+		t.Fatalf("result is a %T", page.Results[0])
+	}
+	if character.HeightCm == nil || *character.HeightCm != 172 {
+		t.Errorf("height cm = %v", character.HeightCm)
+	}
+	if sourceResource := character.GetSourceResource(); sourceResource.ExternalId != api.URL+"/people/1/" {
+		t.Errorf("external id = %q", sourceResource.ExternalId)
+	}
+
+	if _, err = source.FetchResourcePage(context.Background(), domain.ResourceKind("droids"), ""); err == nil {
+		t.Error("expected an error for an unknown kind")
+	}
+}
+
+// TestUpdateFilmFromSource syncs the fake api into the TEST_DATABASE_URL
+// mongo, in a throwaway database.
+func TestUpdateFilmFromSource(t *testing.T) {
+	uri := os.Getenv("TEST_DATABASE_URL")
+	if uri == "" {
+		t.Skip("TEST_DATABASE_URL is not set")
+	}
+
+	ctx := context.Background()
+	dbName := fmt.Sprintf("swapi_test_%d", time.Now().UnixNano())
+	if err := mgm.SetDefaultConfig(nil, dbName, options.Client().ApplyURI(uri)); err != nil {
+		t.Fatal(err)
+	}
+	_, client, db, err := mgm.DefaultConfigs()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		_ = db.Drop(ctx)
+		_ = client.Disconnect(ctx)
+	})
+
+	api := newFakeSwapi(t)
+	source := NewFilmSource(api.URL)
+
+	syncFilms := func() *domain.SyncRun {
+		run := &domain.SyncRun{}
+		if err := domain.UpdateFilmFromSource(ctx, zap.NewNop(), source, run); err != nil {
+			t.Fatal(err)
+		}
+		return run
+	}
+
+	run := syncFilms()
+	if run.PagesFetched != 2 || run.FilmsInserted != 3 || run.FilmsUpdated != 0 || run.FilmsRetired != 0 {
+		t.Errorf("first run = %+v", run)
+	}
+
+	run = syncFilms()
+	if run.FilmsInserted != 0 || run.FilmsUnchanged != 3 || run.HashBefore != run.HashAfter {
+		t.Errorf("second run = %+v", run)
+	}
+
+	// the third film is renamed and then gone from the source
+	api.setSecondPage(filmJSON(3, "Episode III"))
+	run = syncFilms()
+	if run.FilmsUpdated != 1 || run.FilmsUnchanged != 2 || run.HashBefore == run.HashAfter {
+		t.Errorf("third run = %+v", run)
+	}
+
+	api.setSecondPage()
+	run = syncFilms()
+	if run.FilmsRetired != 1 {
+		t.Errorf("fourth run = %+v", run)
+	}
+}